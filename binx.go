@@ -21,6 +21,11 @@ type (
 		UniqueKey() []byte
 	}
 
+	UniqueConstraint interface {
+		Index
+		Unique() bool
+	}
+
 	Queryable interface {
 		Bucket
 		AppendBinary(data []byte) (bool, error)
@@ -42,9 +47,24 @@ type (
 
 func UpperBound(idx Index) Bound                  { return upperBound{idx} }
 func LowerBound(idx Index) Bound                  { return lowerBound{idx} }
+func After(idx Index) Bound                       { return after{idx} }
+func Before(idx Index) Bound                      { return before{idx} }
 func Where(idx Index) Bound                       { return where{idx} }
+func Prefix(idx Index) Bound                      { return prefix{idx} }
 func By(idx Index) Bound                          { return by{idx} }
 func Page(q Queryable, skip, limit int) Queryable { return &page{q, skip, limit} }
+func Desc(q Queryable) Queryable                  { return &desc{q} }
+func Unique(idx Index) UniqueConstraint           { return unique{idx} }
+
+// Multi returns one index entry per key, so a record can be found under
+// several keys of the same index bucket.
+func Multi(b Bucket, keys ...[]byte) []Index {
+	idx := make([]Index, 0, len(keys))
+	for _, k := range keys {
+		idx = append(idx, multi{b.BucketKey(), k})
+	}
+	return idx
+}
 
 type Tx struct {
 	*bolt.Tx
@@ -53,22 +73,102 @@ type Tx struct {
 type (
 	upperBound struct{ Index }
 	lowerBound struct{ Index }
+	after      struct{ Index }
+	before     struct{ Index }
 	where      struct{ Index }
+	prefix     struct{ Index }
 	by         struct{ Index }
 )
 
+// exclusive is implemented by bounds that leave their own key out of range.
+type exclusive interface {
+	exclusive() bool
+}
+
+type unique struct{ Index }
+
+type multi struct {
+	bucket []byte
+	key    []byte
+}
+
+func (m multi) BucketKey() []byte { return m.bucket }
+func (m multi) Key() []byte       { return m.key }
+
+func (u unique) Unique() bool { return true }
+
+// bucketKey names a bucket known only by its key.
+type bucketKey []byte
+
+func (b bucketKey) BucketKey() []byte { return b }
+
 func (b upperBound) Upper() bool { return true }
 func (b upperBound) Lower() bool { return false }
 
 func (b lowerBound) Upper() bool { return false }
 func (b lowerBound) Lower() bool { return true }
 
+func (b after) Upper() bool     { return false }
+func (b after) Lower() bool     { return true }
+func (b after) exclusive() bool { return true }
+
+func (b before) Upper() bool     { return true }
+func (b before) Lower() bool     { return false }
+func (b before) exclusive() bool { return true }
+
 func (b where) Upper() bool { return true }
 func (b where) Lower() bool { return true }
 
+func (b prefix) Upper() bool { return true }
+func (b prefix) Lower() bool { return true }
+
 func (b by) Upper() bool { return false }
 func (b by) Lower() bool { return false }
 
+// wrapper is implemented by queryables that modify how a scan is run, so
+// Scan can find them under other wrappers such as Page.
+type wrapper interface {
+	unwrap() Queryable
+}
+
+type desc struct{ Queryable }
+
+func (d *desc) unwrap() Queryable { return d.Queryable }
+
+// OnDangling makes scans of q call fn for index entries whose record is
+// missing instead of failing with ErrDanglingIndex. The entry is skipped
+// when fn returns nil, otherwise the scan stops with the error returned.
+func OnDangling(q Queryable, fn func(*ErrDanglingIndex) error) Queryable {
+	return &dangling{q, fn}
+}
+
+// SkipDangling makes scans of q silently skip index entries whose record is
+// missing.
+func SkipDangling(q Queryable) Queryable {
+	return OnDangling(q, func(*ErrDanglingIndex) error { return nil })
+}
+
+// Filter makes scans of q leave out the values for which fn returns false,
+// before they reach q or any Page or Cursor wrapping it. fn is handed the
+// primary key and the raw value.
+func Filter(q Queryable, fn func(pk, v []byte) bool) Queryable {
+	return &filter{q, func(pk, v []byte) (bool, error) { return fn(pk, v), nil }}
+}
+
+type filter struct {
+	Queryable
+	fn func(pk, v []byte) (bool, error)
+}
+
+func (f *filter) unwrap() Queryable { return f.Queryable }
+
+type dangling struct {
+	Queryable
+	fn func(*ErrDanglingIndex) error
+}
+
+func (d *dangling) unwrap() Queryable { return d.Queryable }
+
 type page struct {
 	Queryable
 	Skip  int
@@ -87,11 +187,41 @@ func (e *page) AppendBinary(data []byte) (bool, error) {
 	return e.Limit > 0, err
 }
 
+func (e *page) unwrap() Queryable { return e.Queryable }
+
+// Cursor pages through a scan by position rather than by offset. Scan
+// resumes right after Token and stops once Limit items have been appended;
+// afterwards Token holds the position of the last appended item and can be
+// passed to the next Cursor. Fewer than Limit items means the scan is over.
+type Cursor struct {
+	Queryable
+	Limit int
+	Token []byte
+
+	pos []byte
+	n   int
+}
+
+func (c *Cursor) AppendBinary(data []byte) (bool, error) {
+	more, err := c.Queryable.AppendBinary(data)
+	if err != nil {
+		return false, err
+	}
+	c.Token = c.pos
+	c.n++
+	return more && (c.Limit <= 0 || c.n < c.Limit), nil
+}
+
+func (c *Cursor) unwrap() Queryable { return c.Queryable }
+
+// Count counts the values a scan visits in Bucket. Index scans count primary
+// keys straight from the index without reading values.
 type Count struct {
+	Bucket
 	Total int
 }
 
-func (c *Count) AppendBinary([]byte) error {
+func (c *Count) AppendBinary([]byte) (bool, error) {
 	c.Total++
-	return nil
+	return true, nil
 }
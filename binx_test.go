@@ -47,6 +47,30 @@ type index string
 func (e index) BucketKey() []byte { return []byte(indexBucketName) }
 func (e index) Key() []byte       { return []byte(e) }
 
+type uniqueIndexable struct {
+	indexable
+}
+
+func (e *uniqueIndexable) Indexes() []Index {
+	return []Index{
+		Unique(index(e.IndexedField)),
+	}
+}
+
+type taggedIndexable struct {
+	indexable
+	Tags []string
+}
+
+func (e *taggedIndexable) MarshalBinary() ([]byte, error) { return json.Marshal(e) }
+func (e *taggedIndexable) Indexes() []Index {
+	keys := make([][]byte, 0, len(e.Tags))
+	for _, t := range e.Tags {
+		keys = append(keys, []byte(t))
+	}
+	return Multi(index(""), keys...)
+}
+
 type indexableSlice []indexable
 
 func (e *indexableSlice) AppendBinary(data []byte) (bool, error) {
@@ -131,7 +155,8 @@ func bucketState(bkt *bolt.Bucket) (b bucket, err error) {
 			}
 			b[string(k)] = ib
 		} else {
-			b[string(k)] = v
+			// copied, as v is only valid until the transaction ends
+			b[string(k)] = append([]byte{}, v...)
 		}
 		return nil
 	})
@@ -0,0 +1,304 @@
+package binx
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	bolt "github.com/coreos/bbolt"
+	"github.com/pkg/errors"
+)
+
+type ProblemKind int
+
+const (
+	// DanglingIndex is an index entry of a record that is not stored.
+	DanglingIndex ProblemKind = iota + 1
+	// StaleIndex is an index entry of a stored record that its master index
+	// does not list.
+	StaleIndex
+	// MissingIndex is an index entry of a stored record that is not in its
+	// index bucket.
+	MissingIndex
+	// MasterMismatch is a master index entry with no stored record, a stored
+	// record with no master index entry, or a master index entry listing
+	// other index keys than the ones of the record.
+	MasterMismatch
+)
+
+func (k ProblemKind) String() string {
+	switch k {
+	case DanglingIndex:
+		return "dangling index entry"
+	case StaleIndex:
+		return "stale index entry"
+	case MissingIndex:
+		return "missing index entry"
+	case MasterMismatch:
+		return "master index mismatch"
+	}
+	return fmt.Sprintf("ProblemKind(%d)", int(k))
+}
+
+// Problem is an inconsistency found by Check. Bucket and Key name the index
+// entry concerned, they are nil for problems of a whole master index entry.
+type Problem struct {
+	Kind   ProblemKind
+	Bucket []byte
+	Key    []byte
+	PK     []byte
+}
+
+func (p Problem) String() string {
+	if p.Bucket == nil {
+		return fmt.Sprintf("%s: record %s", p.Kind, p.PK)
+	}
+	return fmt.Sprintf("%s: record %s, index %s key %s", p.Kind, p.PK, p.Bucket, p.Key)
+}
+
+// Check cross-verifies the records stored in bucket with their master index
+// entries and index entries. decode turns a stored value back into the
+// Indexable that was put; when it is nil the master index is trusted to list
+// the index entries of each record.
+//
+// The index buckets checked are the ones registered for the collection in s,
+// which may be nil, and the ones its records and master index refer to.
+// Index buckets belong to a single collection, see Schema.Register, so every
+// entry of a record that is not stored in bucket is dangling.
+func (r *Tx) Check(bucket, masterBucket []byte, decode func(value []byte) (Indexable, error), s *Schema) ([]Problem, error) {
+	tx := r.Tx
+
+	owned, err := indexBuckets(tx, bucket, masterBucket, nil, s)
+	if err != nil {
+		return nil, err
+	}
+	bkt, mib := tx.Bucket(bucket), tx.Bucket(masterBucket)
+
+	var (
+		problems []Problem
+		indexes  = map[string]bool{}
+	)
+	for _, b := range owned {
+		indexes[string(b)] = true
+	}
+
+	err = bkt.ForEach(func(pk, v []byte) error {
+		master := masterEntries(mib, pk)
+		if master == nil {
+			problems = append(problems, Problem{Kind: MasterMismatch, PK: copyBytes(pk)})
+		}
+
+		expected := master
+		if decode != nil {
+			idx, err := decode(v)
+			if err != nil {
+				return errors.Wrapf(err, "decode %s", string(pk))
+			}
+			expected = indexEntries(idx.Indexes())
+			if master != nil && !sameEntries(master, expected) {
+				problems = append(problems, Problem{Kind: MasterMismatch, PK: copyBytes(pk)})
+			}
+		}
+
+		for _, b := range sortedKeys(expected) {
+			indexes[b] = true
+			ib := tx.Bucket([]byte(b))
+			for _, k := range expected[b] {
+				var kb *bolt.Bucket
+				if ib != nil {
+					kb = ib.Bucket(k)
+				}
+				if kb == nil || !hasKey(kb, pk) {
+					problems = append(problems, Problem{MissingIndex, []byte(b), k, copyBytes(pk)})
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = mib.ForEach(func(pk, _ []byte) error {
+		if bkt.Get(pk) == nil {
+			problems = append(problems, Problem{Kind: MasterMismatch, PK: copyBytes(pk)})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, b := range sortedKeys(indexes) {
+		ib := tx.Bucket([]byte(b))
+		if ib == nil {
+			continue
+		}
+
+		err := ib.ForEach(func(ik, _ []byte) error {
+			kb := ib.Bucket(ik)
+			if kb == nil {
+				return nil
+			}
+			return kb.ForEach(func(pk, _ []byte) error {
+				kind := ProblemKind(0)
+				switch {
+				case bkt.Get(pk) == nil:
+					kind = DanglingIndex
+				case !hasEntry(masterEntries(mib, pk)[b], ik):
+					kind = StaleIndex
+				default:
+					return nil
+				}
+				problems = append(problems, Problem{kind, []byte(b), copyBytes(ik), copyBytes(pk)})
+				return nil
+			})
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return problems, nil
+}
+
+// Repair runs Check and fixes the problems it finds: dangling and stale
+// index entries are deleted, master index entries of missing records are
+// dropped, and records with missing or mismatching entries are indexed
+// again. It returns the problems fixed.
+func (w *Tx) Repair(bucket, masterBucket []byte, decode func(value []byte) (Indexable, error), s *Schema) ([]Problem, error) {
+	if decode == nil {
+		return nil, errors.New("repair needs a decode function")
+	}
+
+	problems, err := w.Check(bucket, masterBucket, decode, s)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := w.Tx
+	bkt, mib := tx.Bucket(bucket), tx.Bucket(masterBucket)
+
+	var (
+		reindex [][]byte
+		seen    = map[string]bool{}
+	)
+	for _, p := range problems {
+		switch {
+		case p.Kind == DanglingIndex || p.Kind == StaleIndex:
+			if err := deleteIndexEntry(tx.Bucket(p.Bucket), p.Key, p.PK); err != nil {
+				return nil, err
+			}
+		case bkt.Get(p.PK) == nil:
+			if err := mib.DeleteBucket(p.PK); err != nil {
+				return nil, errors.Wrapf(err, "delete master index of %s", string(p.PK))
+			}
+		case !seen[string(p.PK)]:
+			seen[string(p.PK)] = true
+			reindex = append(reindex, p.PK)
+		}
+	}
+
+	for _, pk := range reindex {
+		idx, err := decode(bkt.Get(pk))
+		if err != nil {
+			return nil, errors.Wrapf(err, "decode %s", string(pk))
+		}
+		if err := processIndexable(tx, idx); err != nil {
+			return nil, errors.Wrapf(err, "index %s", string(pk))
+		}
+	}
+
+	return problems, nil
+}
+
+// masterEntries returns the index keys the master index bucket mib holds for
+// the record pk by index bucket, or nil when it holds none.
+func masterEntries(mib *bolt.Bucket, pk []byte) map[string][][]byte {
+	ib := mib.Bucket(pk)
+	if ib == nil {
+		return nil
+	}
+
+	entries := map[string][][]byte{}
+	_ = ib.ForEach(func(k, v []byte) error {
+		if v != nil {
+			entries[string(k)] = [][]byte{copyBytes(v)}
+			return nil
+		}
+		if mb := ib.Bucket(k); mb != nil {
+			_ = mb.ForEach(func(ik, _ []byte) error {
+				entries[string(k)] = append(entries[string(k)], copyBytes(ik))
+				return nil
+			})
+		}
+		return nil
+	})
+	return entries
+}
+
+func indexEntries(indexes []Index) map[string][][]byte {
+	entries := map[string][][]byte{}
+	for _, i := range indexes {
+		b := string(i.BucketKey())
+		if !hasEntry(entries[b], i.Key()) {
+			entries[b] = append(entries[b], i.Key())
+		}
+	}
+	return entries
+}
+
+func sameEntries(a, b map[string][][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, ks := range a {
+		if len(ks) != len(b[k]) {
+			return false
+		}
+		for _, ik := range ks {
+			if !hasEntry(b[k], ik) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func hasEntry(keys [][]byte, key []byte) bool {
+	for _, k := range keys {
+		if bytes.Equal(k, key) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasKey(b *bolt.Bucket, key []byte) bool {
+	k, _ := b.Cursor().Seek(key)
+	return bytes.Equal(k, key)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func copyBytes(b []byte) []byte {
+	return append([]byte(nil), b...)
+}
+
+// Check cross-verifies the stored values of the collection with their
+// indexes, see Tx.Check. s is the schema the collection is registered in.
+func (c *Collection[T]) Check(tx *Tx, s *Schema) ([]Problem, error) {
+	return tx.Check(c.Bucket, c.Master, c.decode, s)
+}
+
+// Repair fixes the problems Check finds, see Tx.Repair.
+func (c *Collection[T]) Repair(tx *Tx, s *Schema) ([]Problem, error) {
+	return tx.Repair(c.Bucket, c.Master, c.decode, s)
+}
@@ -0,0 +1,163 @@
+package binx
+
+import (
+	"testing"
+
+	bolt "github.com/coreos/bbolt"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Tx_Check(t *testing.T) {
+	tests := []struct {
+		name     string
+		corrupt  func(tx *bolt.Tx) error
+		expected []Problem
+	}{
+		{
+			name:    "consistent",
+			corrupt: func(tx *bolt.Tx) error { return nil },
+		},
+		{
+			name: "dangling index entry",
+			corrupt: func(tx *bolt.Tx) error {
+				b, err := tx.Bucket([]byte("byRole")).CreateBucketIfNotExists([]byte("dev"))
+				if err != nil {
+					return err
+				}
+				return b.Put([]byte("id9"), nil)
+			},
+			expected: []Problem{{DanglingIndex, []byte("byRole"), []byte("dev"), []byte("id9")}},
+		},
+		{
+			name: "records deleted with their master index",
+			corrupt: func(tx *bolt.Tx) error {
+				for _, pk := range []string{id1, id2} {
+					if err := tx.Bucket([]byte("users")).Delete([]byte(pk)); err != nil {
+						return err
+					}
+					if err := tx.Bucket([]byte("usersMasterIndex")).DeleteBucket([]byte(pk)); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			expected: []Problem{
+				{DanglingIndex, []byte("byEmail"), []byte("a@example.com"), []byte(id1)},
+				{DanglingIndex, []byte("byEmail"), []byte("b@example.com"), []byte(id2)},
+				{DanglingIndex, []byte("byRole"), []byte("admin"), []byte(id1)},
+				{DanglingIndex, []byte("byRole"), []byte("dev"), []byte(id1)},
+				{DanglingIndex, []byte("byRole"), []byte("dev"), []byte(id2)},
+			},
+		},
+		{
+			name: "stale index entry",
+			corrupt: func(tx *bolt.Tx) error {
+				b, err := tx.Bucket([]byte("byRole")).CreateBucket([]byte("ops"))
+				if err != nil {
+					return err
+				}
+				return b.Put([]byte(id3), nil)
+			},
+			expected: []Problem{{StaleIndex, []byte("byRole"), []byte("ops"), []byte(id3)}},
+		},
+		{
+			name: "missing index entry",
+			corrupt: func(tx *bolt.Tx) error {
+				return tx.Bucket([]byte("byRole")).Bucket([]byte("dev")).Delete([]byte(id1))
+			},
+			expected: []Problem{{MissingIndex, []byte("byRole"), []byte("dev"), []byte(id1)}},
+		},
+		{
+			name: "missing master index entry",
+			corrupt: func(tx *bolt.Tx) error {
+				return tx.Bucket([]byte("usersMasterIndex")).DeleteBucket([]byte(id2))
+			},
+			expected: []Problem{
+				{Kind: MasterMismatch, PK: []byte(id2)},
+				{StaleIndex, []byte("byEmail"), []byte("b@example.com"), []byte(id2)},
+				{StaleIndex, []byte("byRole"), []byte("dev"), []byte(id2)},
+			},
+		},
+		{
+			name: "master index of a missing record",
+			corrupt: func(tx *bolt.Tx) error {
+				return tx.Bucket([]byte("users")).Delete([]byte(id3))
+			},
+			expected: []Problem{
+				{Kind: MasterMismatch, PK: []byte(id3)},
+				{DanglingIndex, []byte("byEmail"), []byte("c@example.com"), []byte(id3)},
+			},
+		},
+		{
+			name: "master index not matching the record",
+			corrupt: func(tx *bolt.Tx) error {
+				v, err := users.codec().Marshal(user{ID: id3, Email: "c@example.com", Roles: []string{"ops"}})
+				if err != nil {
+					return err
+				}
+				return tx.Bucket([]byte("users")).Put([]byte(id3), v)
+			},
+			expected: []Problem{
+				{Kind: MasterMismatch, PK: []byte(id3)},
+				{MissingIndex, []byte("byRole"), []byte("ops"), []byte(id3)},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, teardown := prepUsers(t)
+			defer teardown()
+
+			expected := bucket{}
+			assert.Nil(t, db.db.View(readBuckets(&expected)))
+			assert.Nil(t, db.db.Update(tt.corrupt))
+
+			err := db.View(func(tx *Tx) error {
+				problems, err := users.Check(tx, db.schema)
+				assert.Equal(t, tt.expected, problems)
+				return err
+			})
+			assert.Nil(t, err)
+
+			err = db.Update(func(tx *Tx) error {
+				problems, err := users.Repair(tx, db.schema)
+				assert.Equal(t, tt.expected, problems)
+				return err
+			})
+			assert.Nil(t, err)
+
+			err = db.View(func(tx *Tx) error {
+				problems, err := users.Check(tx, db.schema)
+				assert.Empty(t, problems)
+				return err
+			})
+			assert.Nil(t, err)
+
+			if tt.name == "consistent" {
+				state := bucket{}
+				assert.Nil(t, db.db.View(readBuckets(&state)))
+				assert.Equal(t, expected, state)
+			}
+		})
+	}
+}
+
+func Test_Tx_Check_WithoutDecode(t *testing.T) {
+	db, teardown := prepUsers(t)
+	defer teardown()
+
+	err := db.Update(func(tx *Tx) error {
+		if err := tx.Tx.Bucket([]byte("byRole")).Bucket([]byte("admin")).Delete([]byte(id1)); err != nil {
+			return err
+		}
+
+		problems, err := tx.Check([]byte("users"), []byte("usersMasterIndex"), nil, nil)
+		assert.Equal(t, []Problem{{MissingIndex, []byte("byRole"), []byte("admin"), []byte(id1)}}, problems)
+		assert.Nil(t, err)
+
+		_, err = tx.Repair([]byte("users"), []byte("usersMasterIndex"), nil, nil)
+		assert.EqualError(t, err, "repair needs a decode function")
+		return nil
+	})
+	assert.Nil(t, err)
+}
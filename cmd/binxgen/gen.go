@@ -0,0 +1,509 @@
+package main
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+const directive = "//binx:collection "
+
+type kind int
+
+const (
+	kindString kind = iota + 1
+	kindBytes
+	kindInt
+	kindUint
+	kindFloat
+	kindBool
+	kindTime
+)
+
+var basicKinds = map[string]kind{
+	"string": kindString,
+	"bool":   kindBool,
+	"int":    kindInt, "int8": kindInt, "int16": kindInt, "int32": kindInt, "int64": kindInt, "rune": kindInt,
+	"uint": kindUint, "uint8": kindUint, "uint16": kindUint, "uint32": kindUint, "uint64": kindUint, "byte": kindUint,
+	"float32": kindFloat, "float64": kindFloat,
+}
+
+var codecs = map[string]string{
+	"json":    "binx.JSON",
+	"gob":     "binx.Gob",
+	"msgpack": "binx.Msgpack",
+}
+
+type field struct {
+	Name  string
+	Param string
+	Type  string
+	Kind  kind
+}
+
+type index struct {
+	Func   string
+	Bucket string
+	Fields []field
+	Unique bool
+	Multi  bool
+}
+
+type collection struct {
+	Type    string
+	Bucket  string
+	Codec   string
+	PK      field
+	Indexes []*index
+}
+
+type file struct {
+	Package     string
+	Collections []*collection
+	Indexes     []*index
+}
+
+// generate returns the source of the binx methods for the annotated types of
+// the package in dir, leaving out the previously generated file out.
+func generate(dir, out string) ([]byte, error) {
+	fset := token.NewFileSet()
+
+	names, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+
+	var files []*ast.File
+	for _, name := range names {
+		if strings.HasSuffix(name, "_test.go") || filepath.Base(name) == out {
+			continue
+		}
+		src, err := os.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		f, err := parser.ParseFile(fset, name, src, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	if len(files) == 0 {
+		return nil, errors.Errorf("no Go files in %s", dir)
+	}
+
+	g := &file{Package: files[0].Name.Name}
+	locals := localTypes(files)
+	funcs := map[string]string{}
+
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				ts := spec.(*ast.TypeSpec)
+				doc := ts.Doc
+				if doc == nil && len(gd.Specs) == 1 {
+					doc = gd.Doc
+				}
+				bucket, codec, ok, err := parseDirective(doc)
+				if err != nil {
+					return nil, errors.Wrapf(err, "type %s", ts.Name.Name)
+				}
+				if !ok {
+					continue
+				}
+
+				st, ok := ts.Type.(*ast.StructType)
+				if !ok {
+					return nil, errors.Errorf("type %s is not a struct", ts.Name.Name)
+				}
+
+				c, err := parseCollection(ts.Name.Name, bucket, codec, st, locals)
+				if err != nil {
+					return nil, errors.Wrapf(err, "type %s", ts.Name.Name)
+				}
+
+				for _, i := range c.Indexes {
+					if t, ok := funcs[i.Func]; ok {
+						return nil, errors.Errorf("index constructor %s is generated for %s and %s", i.Func, t, c.Type)
+					}
+					funcs[i.Func] = c.Type
+				}
+
+				g.Collections = append(g.Collections, c)
+				g.Indexes = append(g.Indexes, c.Indexes...)
+			}
+		}
+	}
+
+	if len(g.Collections) == 0 {
+		return nil, errors.Errorf("no types annotated with %q in %s", strings.TrimSpace(directive), dir)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, g); err != nil {
+		return nil, err
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, errors.Wrap(err, "format generated source")
+	}
+	return src, nil
+}
+
+func parseDirective(doc *ast.CommentGroup) (bucket, codec string, ok bool, err error) {
+	if doc == nil {
+		return "", "", false, nil
+	}
+
+	for _, c := range doc.List {
+		if c.Text != strings.TrimSpace(directive) && !strings.HasPrefix(c.Text, directive) {
+			continue
+		}
+
+		args := strings.Fields(strings.TrimPrefix(c.Text, strings.TrimSpace(directive)))
+		if len(args) == 0 {
+			return "", "", false, errors.New("collection bucket is missing")
+		}
+
+		codec = codecs["json"]
+		for _, a := range args[1:] {
+			name := strings.TrimPrefix(a, "codec=")
+			if name == a || codecs[name] == "" {
+				return "", "", false, errors.Errorf("unknown directive argument %q", a)
+			}
+			codec = codecs[name]
+		}
+		return args[0], codec, true, nil
+	}
+
+	return "", "", false, nil
+}
+
+func parseCollection(name, bucket, codec string, st *ast.StructType, locals map[string]ast.Expr) (*collection, error) {
+	c := &collection{Type: name, Bucket: bucket, Codec: codec}
+	indexes := map[string]*index{}
+
+	for _, f := range st.Fields.List {
+		if f.Tag == nil || len(f.Names) != 1 {
+			continue
+		}
+		tag, ok := reflectTag(f.Tag.Value, "binx")
+		if !ok || tag == "-" {
+			continue
+		}
+
+		fname := f.Names[0].Name
+		var (
+			names  []string
+			unique bool
+		)
+		for _, opt := range strings.Split(tag, ",") {
+			switch {
+			case opt == "pk":
+				if c.PK.Name != "" {
+					return nil, errors.Errorf("field %s: primary key is already set", fname)
+				}
+				k, multi := fieldKind(f.Type, locals)
+				if k == 0 || multi {
+					return nil, errors.Errorf("field %s: %s cannot be used as a key", fname, types.ExprString(f.Type))
+				}
+				c.PK = field{Name: fname, Kind: k}
+			case opt == "unique":
+				unique = true
+			case strings.HasPrefix(opt, "index="):
+				names = append(names, strings.TrimPrefix(opt, "index="))
+			default:
+				return nil, errors.Errorf("field %s: unknown binx tag option %q", fname, opt)
+			}
+		}
+		if unique && len(names) == 0 {
+			return nil, errors.Errorf("field %s: unique needs an index", fname)
+		}
+
+		for _, n := range names {
+			k, multi := fieldKind(f.Type, locals)
+			if n == "" || k == 0 {
+				return nil, errors.Errorf("field %s: invalid index %q", fname, n)
+			}
+
+			typ := f.Type
+			if multi {
+				typ = f.Type.(*ast.ArrayType).Elt
+			}
+
+			i, ok := indexes[n]
+			if !ok {
+				i = &index{Func: exported(n), Bucket: bucket + "." + n}
+				indexes[n] = i
+				c.Indexes = append(c.Indexes, i)
+			}
+			i.Fields = append(i.Fields, field{
+				Name:  fname,
+				Param: param(fname),
+				Type:  types.ExprString(typ),
+				Kind:  k,
+			})
+			i.Unique = i.Unique || unique
+			i.Multi = i.Multi || multi
+
+			if i.Multi && len(i.Fields) > 1 {
+				return nil, errors.Errorf("index %s: slice fields cannot be part of a compound index", n)
+			}
+		}
+	}
+
+	if c.PK.Name == "" {
+		return nil, errors.New("no primary key field")
+	}
+	return c, nil
+}
+
+// localTypes maps names of the types declared in files to their definitions.
+func localTypes(files []*ast.File) map[string]ast.Expr {
+	locals := map[string]ast.Expr{}
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			if gd, ok := decl.(*ast.GenDecl); ok && gd.Tok == token.TYPE {
+				for _, spec := range gd.Specs {
+					ts := spec.(*ast.TypeSpec)
+					locals[ts.Name.Name] = ts.Type
+				}
+			}
+		}
+	}
+	return locals
+}
+
+// fieldKind returns how values of type expr are encoded as keys, and whether
+// expr is a slice of such values. A zero kind means expr cannot be a key.
+func fieldKind(expr ast.Expr, locals map[string]ast.Expr) (kind, bool) {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if k, ok := basicKinds[t.Name]; ok {
+			return k, false
+		}
+		if def, ok := locals[t.Name]; ok {
+			if k, multi := fieldKind(def, nil); !multi {
+				return k, false
+			}
+		}
+	case *ast.SelectorExpr:
+		if x, ok := t.X.(*ast.Ident); ok && x.Name == "time" && t.Sel.Name == "Time" {
+			return kindTime, false
+		}
+	case *ast.ArrayType:
+		if t.Len != nil {
+			return 0, false
+		}
+		if k, _ := fieldKind(t.Elt, locals); k == kindUint && isByte(t.Elt) {
+			return kindBytes, false
+		}
+		if k, multi := fieldKind(t.Elt, locals); k != 0 && !multi {
+			return k, true
+		}
+	}
+	return 0, false
+}
+
+func isByte(expr ast.Expr) bool {
+	id, ok := expr.(*ast.Ident)
+	return ok && (id.Name == "byte" || id.Name == "uint8")
+}
+
+func reflectTag(lit, key string) (string, bool) {
+	return reflect.StructTag(strings.Trim(lit, "`")).Lookup(key)
+}
+
+func exported(name string) string {
+	r := []rune(name)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
+
+func param(name string) string {
+	r := []rune(name)
+	r[0] = unicode.ToLower(r[0])
+	p := string(r)
+	if token.Lookup(p).IsKeyword() {
+		p += "_"
+	}
+	return p
+}
+
+// key returns the expression encoding expr, a value of kind k, as a key.
+func key(k kind, expr string) string {
+	switch k {
+	case kindString:
+		return "[]byte(" + expr + ")"
+	case kindBytes:
+		return expr
+	case kindInt:
+		return "keys.Int64(int64(" + expr + "))"
+	case kindUint:
+		return "keys.Uint64(uint64(" + expr + "))"
+	case kindFloat:
+		return "keys.Float64(float64(" + expr + "))"
+	case kindBool:
+		return "binxBool(" + expr + ")"
+	case kindTime:
+		return "keys.Time(" + expr + ")"
+	}
+	return ""
+}
+
+// UsesKeys reports whether the generated code needs the keys package.
+func (g *file) UsesKeys() bool {
+	return g.usesKind(kindInt, kindUint, kindFloat, kindTime)
+}
+
+// UsesBool reports whether the generated code needs binxBool.
+func (g *file) UsesBool() bool {
+	return g.usesKind(kindBool)
+}
+
+func (g *file) usesKind(kinds ...kind) bool {
+	for _, c := range g.Collections {
+		fs := []field{c.PK}
+		for _, i := range c.Indexes {
+			fs = append(fs, i.Fields...)
+		}
+		for _, f := range fs {
+			for _, k := range kinds {
+				if f.Kind == k {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func (g *file) UsesTime() bool {
+	for _, i := range g.Indexes {
+		for _, f := range i.Fields {
+			if strings.Contains(f.Type, "time.") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+var tmpl = template.Must(template.New("binx").Funcs(template.FuncMap{
+	"key": key,
+	"param": func(f field) string {
+		return f.Param
+	},
+}).Parse(`// Code generated by binxgen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+{{- if .UsesTime}}
+	"time"
+{{end}}
+	"github.com/gimalay/binx"
+{{- if .UsesKeys}}
+	"github.com/gimalay/binx/keys"
+{{- end}}
+)
+{{range $c := .Collections}}
+func (v *{{.Type}}) BucketKey() []byte                      { return []byte({{printf "%q" .Bucket}}) }
+func (v *{{.Type}}) MasterIndexBucketKey() []byte           { return []byte({{printf "%q" (print .Bucket "#master")}}) }
+func (v *{{.Type}}) UniqueKey() []byte                      { return {{key .PK.Kind (print "v." .PK.Name)}} }
+func (v *{{.Type}}) MarshalBinary() ([]byte, error)         { return {{.Codec}}.Marshal((*binx{{.Type}})(v)) }
+func (v *{{.Type}}) UnmarshalBinary(data []byte) error      { return {{.Codec}}.Unmarshal(data, (*binx{{.Type}})(v)) }
+func (v *{{.Type}}) AppendBinary(data []byte) (bool, error) { return false, v.UnmarshalBinary(data) }
+
+func (v *{{.Type}}) Indexes() []binx.Index {
+	var idx []binx.Index
+{{- range .Indexes}}
+{{- if .Multi}}
+	for _, e := range v.{{(index .Fields 0).Name}} {
+		if i := {{.Func}}(e); len(i.Key()) > 0 {
+			idx = append(idx, {{if .Unique}}binx.Unique(i){{else}}i{{end}})
+		}
+	}
+{{- else if eq (len .Fields) 1}}
+	if i := {{.Func}}(v.{{(index .Fields 0).Name}}); len(i.Key()) > 0 {
+		idx = append(idx, {{if .Unique}}binx.Unique(i){{else}}i{{end}})
+	}
+{{- else}}
+	idx = append(idx, {{if .Unique}}binx.Unique({{end}}{{.Func}}({{range $n, $f := .Fields}}{{if $n}}, {{end}}v.{{$f.Name}}{{end}}){{if .Unique}}){{end}})
+{{- end}}
+{{- end}}
+	return idx
+}
+
+// binx{{.Type}} drops the methods of {{.Type}}, so that codecs honouring
+// encoding.BinaryMarshaler do not call back into MarshalBinary.
+type binx{{.Type}} {{.Type}}
+
+// {{.Type}}IndexBuckets lists the index buckets of {{.Type}}, to be passed
+// to binx.Schema.Register.
+var {{.Type}}IndexBuckets = []binx.Bucket{
+{{- range .Indexes}}
+	binxIndex{bucket: []byte({{printf "%q" .Bucket}})},
+{{- end}}
+}
+
+// {{.Type}}Slice collects {{.Type}} values found by a scan.
+type {{.Type}}Slice []{{.Type}}
+
+func (s {{.Type}}Slice) BucketKey() []byte { return []byte({{printf "%q" .Bucket}}) }
+
+func (s *{{.Type}}Slice) AppendBinary(data []byte) (bool, error) {
+	var v {{.Type}}
+	if err := v.UnmarshalBinary(data); err != nil {
+		return false, err
+	}
+	*s = append(*s, v)
+	return true, nil
+}
+{{range .Indexes}}
+// {{.Func}} returns the entry of {{$c.Type}} index {{.Bucket}}.
+func {{.Func}}({{range $n, $f := .Fields}}{{if $n}}, {{end}}{{param $f}} {{$f.Type}}{{end}}) binx.Index {
+{{- if eq (len .Fields) 1}}
+	return binxIndex{[]byte({{printf "%q" .Bucket}}), {{key (index .Fields 0).Kind (param (index .Fields 0))}}}
+{{- else}}
+	return binx.Compound(binxIndex{bucket: []byte({{printf "%q" .Bucket}})},
+{{- range .Fields}}
+		{{key .Kind (param .)}},
+{{- end}}
+	)
+{{- end}}
+}
+{{end}}
+{{- end}}
+type binxIndex struct{ bucket, key []byte }
+
+func (i binxIndex) BucketKey() []byte { return i.bucket }
+func (i binxIndex) Key() []byte       { return i.key }
+{{- if .UsesBool}}
+
+func binxBool(v bool) []byte {
+	if v {
+		return []byte{1}
+	}
+	return []byte{0}
+}
+{{- end}}
+`))
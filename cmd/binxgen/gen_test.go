@@ -0,0 +1,123 @@
+package main
+
+import (
+	"flag"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func Test_Generate_Golden(t *testing.T) {
+	got, err := generate(filepath.Join("testdata", "model"), "binx_gen.go")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	typeCheck(t, filepath.Join("testdata", "model"), got)
+
+	golden := filepath.Join("testdata", "model.golden")
+	if *update {
+		if err := os.WriteFile(golden, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, string(want), string(got))
+}
+
+// typeCheck type-checks the package in dir along with generated, so that
+// output which does not compile is never taken as golden.
+func typeCheck(t *testing.T, dir string, generated []byte) {
+	t.Helper()
+
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var files []*ast.File
+	for _, p := range pkgs {
+		for _, f := range p.Files {
+			files = append(files, f)
+		}
+	}
+	f, err := parser.ParseFile(fset, "binx_gen.go", generated, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files = append(files, f)
+
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	if _, err := conf.Check(f.Name.Name, fset, files, nil); err != nil {
+		t.Fatalf("generated code does not compile: %v", err)
+	}
+}
+
+func Test_Generate_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		err  string
+	}{
+		{"no annotated types", `type User struct{ ID string }`,
+			`no types annotated with "//binx:collection"`},
+		{"missing bucket", "//binx:collection\ntype User struct{ ID string `binx:\"pk\"` }",
+			"type User: collection bucket is missing"},
+		{"unknown codec", "//binx:collection users codec=xml\ntype User struct{ ID string `binx:\"pk\"` }",
+			`type User: unknown directive argument "codec=xml"`},
+		{"not a struct", "//binx:collection users\ntype User string",
+			"type User is not a struct"},
+		{"missing pk", "//binx:collection users\ntype User struct{ ID string }",
+			"type User: no primary key field"},
+		{"two pks", "//binx:collection users\ntype User struct {\n" +
+			"ID string `binx:\"pk\"`\n" +
+			"Name string `binx:\"pk\"`\n}",
+			"type User: field Name: primary key is already set"},
+		{"unsupported key", "//binx:collection users\ntype User struct{ ID map[string]int `binx:\"pk\"` }",
+			"type User: field ID: map[string]int cannot be used as a key"},
+		{"unique without index", "//binx:collection users\ntype User struct{ ID string `binx:\"pk,unique\"` }",
+			"type User: field ID: unique needs an index"},
+		{"unknown option", "//binx:collection users\ntype User struct{ ID string `binx:\"pk,sparse\"` }",
+			`type User: field ID: unknown binx tag option "sparse"`},
+		{"compound slice", "//binx:collection users\ntype User struct {\n" +
+			"ID string `binx:\"pk\"`\n" +
+			"Tags []string `binx:\"index=byTag\"`\n" +
+			"Name string `binx:\"index=byTag\"`\n}",
+			"type User: index byTag: slice fields cannot be part of a compound index"},
+		{"duplicate constructor", "//binx:collection users\ntype User struct {\n" +
+			"ID string `binx:\"pk\"`\n" +
+			"Email string `binx:\"index=byEmail\"`\n}\n" +
+			"//binx:collection admins\ntype Admin struct {\n" +
+			"ID string `binx:\"pk\"`\n" +
+			"Email string `binx:\"index=byEmail\"`\n}",
+			"index constructor ByEmail is generated for User and Admin"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			src := "package model\n\n" + tt.src + "\n"
+			if err := os.WriteFile(filepath.Join(dir, "model.go"), []byte(src), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := generate(dir, "binx_gen.go")
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+// Command binxgen generates binx.Indexable boilerplate for struct types.
+//
+// Types are picked by a directive in their doc comment naming the bucket
+// they are stored in and, optionally, the binx codec used to encode them:
+//
+//	//binx:collection users codec=msgpack
+//	type User struct {
+//		ID      string    `binx:"pk"`
+//		Email   string    `binx:"index=byEmail,unique"`
+//		Roles   []string  `binx:"index=byRole"`
+//		Tenant  string    `binx:"index=byTenantCreated"`
+//		Created time.Time `binx:"index=byTenantCreated"`
+//	}
+//
+// Fields are tagged as for binx.Tagged. For every type binxgen writes the
+// binx.Indexable and binx.Queryable methods and a slice collector, and for
+// every index a constructor such as ByEmail(string) binx.Index. The index
+// buckets of a type are listed in a variable for binx.Schema:
+//
+//	s.Register(&User{}, UserIndexBuckets...)
+//
+// Usage:
+//
+//	//go:generate binxgen [-o binx_gen.go] [dir]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+func main() {
+	out := flag.String("o", "binx_gen.go", "output file name, relative to the package directory")
+	flag.Parse()
+
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+
+	src, err := generate(dir, *out)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "binxgen:", err)
+		os.Exit(1)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, *out), src, 0644); err != nil {
+		fmt.Fprintln(os.Stderr, "binxgen:", err)
+		os.Exit(1)
+	}
+}
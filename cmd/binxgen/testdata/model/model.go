@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+type Status string
+
+//binx:collection users
+type User struct {
+	ID      string    `binx:"pk"`
+	Email   string    `binx:"index=byEmail,unique"`
+	Roles   []string  `binx:"index=byRole"`
+	Tenant  string    `binx:"index=byTenantCreated"`
+	Created time.Time `binx:"index=byTenantCreated"`
+	Status  Status    `binx:"index=byStatus"`
+	Name    string
+}
+
+// Order is stored with msgpack.
+//
+//binx:collection orders codec=msgpack
+type Order struct {
+	ID      int64   `binx:"pk"`
+	Total   float64 `binx:"index=byTotal"`
+	Paid    bool    `binx:"index=byPaid"`
+	Type    uint8   `binx:"index=byType"`
+	Payload []byte  `binx:"index=byPayload"`
+}
+
+type notStored struct {
+	ID string `binx:"pk"`
+}
@@ -0,0 +1,84 @@
+package binx
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"reflect"
+
+	"github.com/pkg/errors"
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
+)
+
+// Codec encodes values stored by a Collection.
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+var (
+	// JSON is the Codec used by collections that do not set one.
+	JSON Codec = jsonCodec{}
+	Gob  Codec = gobCodec{}
+
+	Msgpack Codec = msgpackCodec{}
+
+	// Protobuf encodes values implementing proto.Message, such as the
+	// pointers to generated message structs a Collection is declared over.
+	Protobuf Codec = protobufCodec{}
+)
+
+type (
+	jsonCodec     struct{}
+	gobCodec      struct{}
+	msgpackCodec  struct{}
+	protobufCodec struct{}
+)
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+
+func (gobCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(v)
+	return buf.Bytes(), err
+}
+
+func (gobCodec) Unmarshal(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+func (msgpackCodec) Marshal(v interface{}) ([]byte, error)      { return msgpack.Marshal(v) }
+func (msgpackCodec) Unmarshal(data []byte, v interface{}) error { return msgpack.Unmarshal(data, v) }
+
+func (protobufCodec) Marshal(v interface{}) ([]byte, error) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, errors.Errorf("%T is not a proto.Message", v)
+	}
+	return proto.Marshal(m)
+}
+
+// Unmarshal accepts a message or a pointer to a message pointer, which is
+// allocated when nil.
+func (protobufCodec) Unmarshal(data []byte, v interface{}) error {
+	if m, ok := v.(proto.Message); ok {
+		return proto.Unmarshal(data, m)
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Ptr {
+		return errors.Errorf("%T is not a proto.Message", v)
+	}
+
+	if rv.Elem().IsNil() {
+		rv.Elem().Set(reflect.New(rv.Elem().Type().Elem()))
+	}
+
+	m, ok := rv.Elem().Interface().(proto.Message)
+	if !ok {
+		return errors.Errorf("%T is not a proto.Message", v)
+	}
+	return proto.Unmarshal(data, m)
+}
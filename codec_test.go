@@ -0,0 +1,79 @@
+package binx
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func Test_Codec(t *testing.T) {
+	u := user{ID: id1, Email: "a@example.com", Roles: []string{"admin"}}
+
+	for name, codec := range map[string]Codec{"json": JSON, "gob": Gob, "msgpack": Msgpack} {
+		t.Run(name, func(t *testing.T) {
+			data, err := codec.Marshal(u)
+			assert.Nil(t, err)
+
+			var v user
+			assert.Nil(t, codec.Unmarshal(data, &v))
+			assert.Equal(t, u, v)
+		})
+	}
+
+	t.Run("protobuf", func(t *testing.T) {
+		data, err := Protobuf.Marshal(wrapperspb.String(value1))
+		assert.Nil(t, err)
+
+		var v *wrapperspb.StringValue
+		assert.Nil(t, Protobuf.Unmarshal(data, &v))
+		assert.True(t, proto.Equal(wrapperspb.String(value1), v))
+
+		m := &wrapperspb.StringValue{}
+		assert.Nil(t, Protobuf.Unmarshal(data, m))
+		assert.Equal(t, value1, m.Value)
+
+		_, err = Protobuf.Marshal(u)
+		assert.NotNil(t, err)
+		assert.NotNil(t, Protobuf.Unmarshal(data, &u))
+	})
+}
+
+func Test_Collection_Codec(t *testing.T) {
+	_ = os.Remove(dbPath)
+
+	names := &Collection[*wrapperspb.StringValue]{
+		Bucket: []byte(bucketName),
+		Master: []byte(masterIndexBucketName),
+		Codec:  Protobuf,
+		Key:    func(v *wrapperspb.StringValue) []byte { return []byte(v.Value) },
+		Indexes: func(v *wrapperspb.StringValue) []Index {
+			return []Index{index(v.Value)}
+		},
+	}
+	s := &Schema{}
+	names.Register(s, index(""))
+
+	db, err := Open(dbPath, 0600, nil, s)
+	assert.Nil(t, err)
+	defer func() { assert.Nil(t, db.Close()) }()
+
+	err = db.Update(func(tx *Tx) error {
+		return names.Put(tx, wrapperspb.String(value1))
+	})
+	assert.Nil(t, err)
+
+	err = db.View(func(tx *Tx) error {
+		v, err := names.Get(tx, []byte(value1))
+		assert.Nil(t, err)
+		assert.Equal(t, value1, v.GetValue())
+
+		vs, err := names.Scan(tx, Where(index(value1)))
+		assert.Nil(t, err)
+		assert.Len(t, vs, 1)
+		return nil
+	})
+	assert.Nil(t, err)
+}
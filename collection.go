@@ -0,0 +1,146 @@
+package binx
+
+import (
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+// Collection stores values of T in Bucket, encoded with Codec, and keeps
+// their indexes in the master index bucket Master. Key returns the primary
+// key of a value and Indexes, which may be nil, its index entries.
+type Collection[T any] struct {
+	Bucket  []byte
+	Master  []byte
+	Codec   Codec
+	Key     func(T) []byte
+	Indexes func(T) []Index
+
+	dangling func(*ErrDanglingIndex) error
+}
+
+func (c *Collection[T]) BucketKey() []byte { return c.Bucket }
+
+func (c *Collection[T]) Get(tx *Tx, key []byte) (T, error) {
+	var vs []T
+	if err := tx.Get(c.Slice(&vs), key); err != nil {
+		var zero T
+		return zero, err
+	}
+	return vs[0], nil
+}
+
+func (c *Collection[T]) Put(tx *Tx, v T) error {
+	return tx.Put(c.Indexable(v))
+}
+
+// Indexable returns v as stored by Put.
+func (c *Collection[T]) Indexable(v T) Indexable {
+	return item[T]{c, v}
+}
+
+func (c *Collection[T]) Delete(tx *Tx, key []byte) error {
+	return tx.DeleteByKey(c.Bucket, c.Master, key)
+}
+
+func (c *Collection[T]) Scan(tx *Tx, bns ...Bound) ([]T, error) {
+	var vs []T
+	err := tx.Scan(c.query(&vs), bns)
+	return vs, err
+}
+
+// OnDangling returns a copy of the collection whose Scan and All call fn for
+// index entries whose record is missing, see binx.OnDangling.
+func (c *Collection[T]) OnDangling(fn func(*ErrDanglingIndex) error) *Collection[T] {
+	d := *c
+	d.dangling = fn
+	return &d
+}
+
+// query returns the Queryable of Scan and All, appending values to dst.
+func (c *Collection[T]) query(dst *[]T) Queryable {
+	q := c.Slice(dst)
+	if c.dangling != nil {
+		q = OnDangling(q, c.dangling)
+	}
+	return q
+}
+
+// Slice returns a Queryable appending scanned values to dst, for scans that
+// wrap it with Page, Desc or Cursor.
+func (c *Collection[T]) Slice(dst *[]T) Queryable {
+	return &collector[T]{c: c, dst: dst}
+}
+
+// Filter makes scans of q leave out the values for which fn returns false,
+// see binx.Filter. Values are decoded for fn on their own.
+func (c *Collection[T]) Filter(q Queryable, fn func(T) bool) Queryable {
+	return &filter{q, func(_, data []byte) (bool, error) {
+		var v T
+		if err := c.codec().Unmarshal(data, &v); err != nil {
+			return false, errors.Wrap(err, "unmarshal")
+		}
+		return fn(v), nil
+	}}
+}
+
+// Register adds the buckets of the collection to s. Index buckets are taken
+// from the Indexes of the zero value of T, see Schema.Register. When T is a
+// pointer type its zero value is nil, so Indexes is not called and indexes
+// have to list all index buckets of the collection.
+func (c *Collection[T]) Register(s *Schema, indexes ...Bucket) {
+	var v T
+	var idx Indexable = item[T]{c, v}
+	if reflect.TypeOf((*T)(nil)).Elem().Kind() == reflect.Ptr {
+		idx = subset{Indexable: idx}
+	}
+	s.Register(idx, indexes...)
+}
+
+// decode returns the Indexable a stored value was put as.
+func (c *Collection[T]) decode(value []byte) (Indexable, error) {
+	var v T
+	if err := c.codec().Unmarshal(value, &v); err != nil {
+		return nil, err
+	}
+	return c.Indexable(v), nil
+}
+
+func (c *Collection[T]) codec() Codec {
+	if c.Codec == nil {
+		return JSON
+	}
+	return c.Codec
+}
+
+type item[T any] struct {
+	c *Collection[T]
+	v T
+}
+
+func (i item[T]) BucketKey() []byte              { return i.c.Bucket }
+func (i item[T]) MasterIndexBucketKey() []byte   { return i.c.Master }
+func (i item[T]) UniqueKey() []byte              { return i.c.Key(i.v) }
+func (i item[T]) MarshalBinary() ([]byte, error) { return i.c.codec().Marshal(i.v) }
+func (i item[T]) Indexes() []Index {
+	if i.c.Indexes == nil {
+		return nil
+	}
+	return i.c.Indexes(i.v)
+}
+
+type collector[T any] struct {
+	c   *Collection[T]
+	dst *[]T
+}
+
+func (s *collector[T]) BucketKey() []byte { return s.c.Bucket }
+
+func (s *collector[T]) AppendBinary(data []byte) (bool, error) {
+	var v T
+	if err := s.c.codec().Unmarshal(data, &v); err != nil {
+		return false, errors.Wrap(err, "unmarshal")
+	}
+	*s.dst = append(*s.dst, v)
+	return true, nil
+}
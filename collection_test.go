@@ -0,0 +1,116 @@
+package binx
+
+import (
+	"os"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type user struct {
+	ID    string
+	Email string
+	Roles []string
+}
+
+type userIndex struct{ name, key string }
+
+func (e userIndex) BucketKey() []byte { return []byte(e.name) }
+func (e userIndex) Key() []byte       { return []byte(e.key) }
+
+func byEmail(email string) Index { return userIndex{"byEmail", email} }
+func byRole(role string) Index   { return userIndex{"byRole", role} }
+
+var users = &Collection[user]{
+	Bucket: []byte("users"),
+	Master: []byte("usersMasterIndex"),
+	Key:    func(u user) []byte { return []byte(u.ID) },
+	Indexes: func(u user) []Index {
+		roles := make([][]byte, 0, len(u.Roles))
+		for _, r := range u.Roles {
+			roles = append(roles, []byte(r))
+		}
+		return append(Multi(byRole(""), roles...), Unique(byEmail(u.Email)))
+	},
+}
+
+func prepUsers(t *testing.T) (*DB, func()) {
+	_ = os.Remove(dbPath)
+
+	s := &Schema{}
+	users.Register(s, byRole(""))
+
+	db, err := Open(dbPath, 0600, nil, s)
+	assert.Nil(t, err)
+
+	err = db.Update(func(tx *Tx) error {
+		for _, u := range []user{
+			{ID: id1, Email: "a@example.com", Roles: []string{"admin", "dev"}},
+			{ID: id2, Email: "b@example.com", Roles: []string{"dev"}},
+			{ID: id3, Email: "c@example.com"},
+		} {
+			if err := users.Put(tx, u); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	assert.Nil(t, err)
+
+	return db, func() { assert.Nil(t, db.Close()) }
+}
+
+func Test_Collection(t *testing.T) {
+	db, teardown := prepUsers(t)
+	defer teardown()
+
+	err := db.View(func(tx *Tx) error {
+		u, err := users.Get(tx, []byte(id2))
+		assert.Nil(t, err)
+		assert.Equal(t, user{ID: id2, Email: "b@example.com", Roles: []string{"dev"}}, u)
+
+		_, err = users.Get(tx, []byte("missing"))
+		assert.Equal(t, ErrNotFound, err)
+
+		devs, err := users.Scan(tx, Where(byRole("dev")))
+		assert.Nil(t, err)
+		assert.Len(t, devs, 2)
+
+		var last []user
+		err = tx.Scan(Desc(Page(users.Slice(&last), 0, 1)), nil)
+		assert.Nil(t, err)
+		assert.Equal(t, []user{{ID: id3, Email: "c@example.com"}}, last)
+
+		var admins []user
+		q := users.Filter(users.Slice(&admins), func(u user) bool { return len(u.Roles) > 1 })
+		err = tx.Scan(Page(q, 0, 1), []Bound{Where(byRole("dev"))})
+		assert.Nil(t, err)
+		assert.Equal(t, []user{{ID: id1, Email: "a@example.com", Roles: []string{"admin", "dev"}}}, admins)
+		return nil
+	})
+	assert.Nil(t, err)
+
+	err = db.Update(func(tx *Tx) error {
+		err := users.Put(tx, user{ID: "id4", Email: "a@example.com"})
+		var violation *ErrUniqueViolation
+		assert.True(t, errors.As(err, &violation))
+
+		assert.Nil(t, users.Delete(tx, []byte(id1)))
+		assert.Equal(t, ErrNotFound, users.Delete(tx, []byte(id1)))
+		return nil
+	})
+	assert.Nil(t, err)
+
+	err = db.View(func(tx *Tx) error {
+		all, err := users.Scan(tx)
+		assert.Nil(t, err)
+		assert.Len(t, all, 2)
+
+		admins, err := users.Scan(tx, Where(byRole("admin")))
+		assert.Nil(t, err)
+		assert.Empty(t, admins)
+		return nil
+	})
+	assert.Nil(t, err)
+}
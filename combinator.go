@@ -0,0 +1,124 @@
+package binx
+
+import (
+	"bytes"
+	"sort"
+
+	bolt "github.com/coreos/bbolt"
+)
+
+// And matches records matched by all of bns, which may be bounds on
+// different indexes. Not operands are subtracted from the result. The
+// operand expected to match the fewest records is walked first, see Explain.
+func And(bns ...Bound) Bound { return &combinator{opAnd, bns} }
+
+// Or matches records matched by any of bns.
+func Or(bns ...Bound) Bound { return &combinator{opOr, bns} }
+
+// Not matches records of the scanned bucket that b does not match.
+func Not(b Bound) Bound { return &combinator{opNot, []Bound{b}} }
+
+type op int
+
+const (
+	opAnd op = iota
+	opOr
+	opNot
+)
+
+// combinator is a Bound computed from the primary keys other bounds find in
+// their index buckets. Scan treats a list of bounds holding one as their
+// And. Values are only read for the records of the final result, in primary
+// key order.
+type combinator struct {
+	op  op
+	bns []Bound
+}
+
+func (c *combinator) BucketKey() []byte { return nil }
+func (c *combinator) Key() []byte       { return nil }
+func (c *combinator) Upper() bool       { return false }
+func (c *combinator) Lower() bool       { return false }
+
+func combined(bns []Bound) Bound {
+	for _, b := range bns {
+		if _, ok := b.(*combinator); !ok {
+			continue
+		}
+		if len(bns) == 1 {
+			return b
+		}
+		return And(bns...)
+	}
+	return nil
+}
+
+func listCombined(r *bolt.Tx, q Queryable, c Bound, o options) error {
+	bkt := r.Bucket(q.BucketKey())
+	if bkt == nil {
+		return ErrIdxNotFound
+	}
+
+	if o.resume != nil && len(o.resume) != 1 {
+		return errInvalidToken
+	}
+
+	p, err := planBound(r, q, c, noCount)
+	if err != nil {
+		return err
+	}
+	set, err := p.keys(r, q)
+	if err != nil {
+		return err
+	}
+
+	pks := make([]string, 0, len(set))
+	for pk := range set {
+		pks = append(pks, pk)
+	}
+	if o.desc {
+		sort.Sort(sort.Reverse(sort.StringSlice(pks)))
+	} else {
+		sort.Strings(pks)
+	}
+
+	for _, s := range pks {
+		pk := []byte(s)
+		if o.resume != nil {
+			if c := bytes.Compare(pk, o.resume[0]); c == 0 || (c < 0) != o.desc {
+				continue
+			}
+		}
+
+		var v []byte
+		if !o.keysOnly {
+			if v = bkt.Get(pk); v == nil {
+				if err := o.skipDangling(nil, nil, pk); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		more, err := o.append(q, nil, pk, v)
+		if err != nil || !more {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// scanKeys returns the primary keys an ordinary Scan with bns would visit.
+func scanKeys(r *bolt.Tx, b Bucket, bns []Bound) (map[string]bool, error) {
+	set := map[string]bool{}
+	// the bare bucket key keeps the wrappers of b out of the key scans
+	y := &yielder{Bucket: bucketKey(b.BucketKey()), keysOnly: true, yield: func(pk, _ []byte) bool {
+		set[string(pk)] = true
+		return true
+	}}
+	if err := (&Tx{r}).Scan(y, bns); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
@@ -0,0 +1,136 @@
+package binx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Tx_Scan_Combinators(t *testing.T) {
+	db, teardown := prepUsers(t)
+	defer teardown()
+
+	ids := func(vs []user) []string {
+		var ids []string
+		for _, v := range vs {
+			ids = append(ids, v.ID)
+		}
+		return ids
+	}
+
+	tests := []struct {
+		name          string
+		bounds        []Bound
+		argument      func(q Queryable) Queryable
+		expected      []string
+		expectedError error
+	}{
+		{
+			name:     "and",
+			bounds:   []Bound{And(Where(byRole("dev")), Where(byEmail("a@example.com")))},
+			expected: []string{id1},
+		},
+		{
+			name:     "or",
+			bounds:   []Bound{Or(Where(byEmail("c@example.com")), Where(byRole("admin")))},
+			expected: []string{id1, id3},
+		},
+		{
+			name:     "not",
+			bounds:   []Bound{Not(Where(byRole("dev")))},
+			expected: []string{id3},
+		},
+		{
+			name:     "list of bounds with not",
+			bounds:   []Bound{Where(byRole("dev")), Not(Where(byRole("admin")))},
+			expected: []string{id2},
+		},
+		{
+			name:     "range as and of bounds",
+			bounds:   []Bound{And(After(byEmail("a@example.com")), Before(byEmail("c@example.com")))},
+			expected: []string{id2},
+		},
+		{
+			name:     "nested",
+			bounds:   []Bound{Or(And(Where(byRole("dev")), Not(Where(byRole("admin")))), Where(byEmail("c@example.com")))},
+			expected: []string{id2, id3},
+		},
+		{
+			name:     "desc",
+			bounds:   []Bound{Or(Where(byEmail("c@example.com")), Where(byRole("dev")))},
+			argument: Desc,
+			expected: []string{id3, id2, id1},
+		},
+		{
+			name:     "page",
+			bounds:   []Bound{Or(Where(byEmail("c@example.com")), Where(byRole("dev")))},
+			argument: func(q Queryable) Queryable { return Page(q, 1, 1) },
+			expected: []string{id2},
+		},
+		{
+			name:          "missing index bucket",
+			bounds:        []Bound{And(Where(byRole("dev")), Where(userIndex{"byName", "a"}))},
+			expectedError: ErrIdxNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var vs []user
+			q := users.Slice(&vs)
+			if tt.argument != nil {
+				q = tt.argument(q)
+			}
+
+			err := db.View(func(tx *Tx) error { return tx.Scan(q, tt.bounds) })
+			if tt.expectedError != nil {
+				assert.Equal(t, tt.expectedError, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expected, ids(vs))
+		})
+	}
+}
+
+func Test_Tx_Scan_Combinators_CursorAndCount(t *testing.T) {
+	db, teardown := prepUsers(t)
+	defer teardown()
+
+	bns := []Bound{Or(Where(byEmail("c@example.com")), Where(byRole("dev")))}
+
+	var (
+		vs    []user
+		token []byte
+	)
+	for i := 0; i < 3; i++ {
+		c := &Cursor{Queryable: users.Slice(&vs), Limit: 1, Token: token}
+		assert.Nil(t, db.View(func(tx *Tx) error { return tx.Scan(c, bns) }))
+		token = c.Token
+	}
+	assert.Len(t, vs, 3)
+	assert.Equal(t, []string{id1, id2, id3}, []string{vs[0].ID, vs[1].ID, vs[2].ID})
+
+	c := &Count{Bucket: users}
+	assert.Nil(t, db.View(func(tx *Tx) error { return tx.Scan(c, bns) }))
+	assert.Equal(t, 3, c.Total)
+}
+
+func Test_Tx_Scan_Combinators_Dangling(t *testing.T) {
+	db, teardown := prepUsers(t)
+	defer teardown()
+
+	err := db.Update(func(tx *Tx) error {
+		return tx.Tx.Bucket([]byte("users")).Delete([]byte(id2))
+	})
+	assert.Nil(t, err)
+
+	var vs []user
+	bns := []Bound{And(Where(byRole("dev")))}
+	err = db.View(func(tx *Tx) error { return tx.Scan(users.Slice(&vs), bns) })
+	assert.Equal(t, &ErrDanglingIndex{PK: []byte(id2)}, err)
+
+	vs = nil
+	err = db.View(func(tx *Tx) error { return tx.Scan(SkipDangling(users.Slice(&vs)), bns) })
+	assert.Nil(t, err)
+	assert.Equal(t, []user{{ID: id1, Email: "a@example.com", Roles: []string{"admin", "dev"}}}, vs)
+}
@@ -0,0 +1,89 @@
+package binx
+
+import (
+	"os"
+
+	bolt "github.com/coreos/bbolt"
+	"github.com/pkg/errors"
+)
+
+// DB owns a bolt database and runs binx transactions on it.
+type DB struct {
+	db     *bolt.DB
+	schema *Schema
+}
+
+// Open opens the bolt database at path. Buckets of schema are created, or
+// only checked when the database is opened read-only. schema may be nil.
+func Open(path string, mode os.FileMode, options *bolt.Options, schema *Schema) (*DB, error) {
+	b, err := bolt.Open(path, mode, options)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &DB{db: b, schema: schema}
+	if schema == nil {
+		return d, nil
+	}
+
+	if options != nil && options.ReadOnly {
+		err = d.View(func(tx *Tx) error { return tx.CheckSchema(schema) })
+	} else {
+		err = d.Update(func(tx *Tx) error { return tx.EnsureSchema(schema) })
+	}
+	if err != nil {
+		_ = b.Close()
+		return nil, errors.Wrap(err, "apply schema")
+	}
+
+	return d, nil
+}
+
+func (d *DB) View(fn func(*Tx) error) error {
+	return d.db.View(func(tx *bolt.Tx) error { return fn(&Tx{tx}) })
+}
+
+func (d *DB) Update(fn func(*Tx) error) error {
+	return d.db.Update(func(tx *bolt.Tx) error { return fn(&Tx{tx}) })
+}
+
+// Batch runs fn in a write transaction that may be shared with other
+// concurrent Batch calls, see bolt.DB.Batch. fn may be run more than once.
+func (d *DB) Batch(fn func(*Tx) error) error {
+	return d.db.Batch(func(tx *bolt.Tx) error { return fn(&Tx{tx}) })
+}
+
+// PruneIndexes deletes the empty nested buckets of index keys in indexes,
+// or in all index buckets of the schema when none are given, with one
+// transaction per index bucket. See Tx.PruneIndexes.
+func (d *DB) PruneIndexes(indexes ...Bucket) (int, error) {
+	if len(indexes) == 0 && d.schema != nil {
+		seen := map[string]bool{}
+		for _, c := range d.schema.collections {
+			for _, i := range c.indexes {
+				if !seen[string(i)] {
+					seen[string(i)] = true
+					indexes = append(indexes, bucketKey(i))
+				}
+			}
+		}
+	}
+
+	n := 0
+	for _, i := range indexes {
+		var p int
+		err := d.Update(func(tx *Tx) (err error) {
+			p, err = tx.PruneIndexes(i)
+			return err
+		})
+		if err != nil {
+			return n, err
+		}
+		n += p
+	}
+	return n, nil
+}
+
+func (d *DB) Close() error {
+	return d.db.Close()
+}
@@ -0,0 +1,102 @@
+package binx
+
+import (
+	"os"
+	"sync"
+	"testing"
+
+	bolt "github.com/coreos/bbolt"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_DB(t *testing.T) {
+	_ = os.Remove(dbPath)
+
+	s := &Schema{}
+	s.Register(&indexable{})
+
+	db, err := Open(dbPath, 0600, nil, s)
+	assert.Nil(t, err)
+
+	err = db.Update(func(tx *Tx) error {
+		return tx.Put(&indexable{ID: id1, IndexedField: value1})
+	})
+	assert.Nil(t, err)
+
+	var wg sync.WaitGroup
+	for _, id := range []string{id2, id3} {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			err := db.Batch(func(tx *Tx) error {
+				return tx.Put(&indexable{ID: id, IndexedField: value2})
+			})
+			assert.Nil(t, err)
+		}(id)
+	}
+	wg.Wait()
+	assert.Nil(t, db.Close())
+
+	db, err = Open(dbPath, 0600, &bolt.Options{ReadOnly: true}, s)
+	assert.Nil(t, err)
+	defer func() { assert.Nil(t, db.Close()) }()
+
+	sl := indexableSlice{}
+	err = db.View(func(tx *Tx) error {
+		return tx.Scan(&sl, []Bound{Where(index(value2))})
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, indexableSlice{
+		indexable{ID: id2, IndexedField: value2},
+		indexable{ID: id3, IndexedField: value2},
+	}, sl)
+}
+
+func Test_DB_Open_MissingSchema(t *testing.T) {
+	_ = os.Remove(dbPath)
+
+	db, err := Open(dbPath, 0600, nil, nil)
+	assert.Nil(t, err)
+	assert.Nil(t, db.Close())
+
+	s := &Schema{}
+	s.Register(&indexable{})
+
+	_, err = Open(dbPath, 0600, &bolt.Options{ReadOnly: true}, s)
+	assert.EqualError(t, err, "apply schema: bucket bucketName not found")
+}
+
+func Test_DB_PruneIndexes(t *testing.T) {
+	_ = os.Remove(dbPath)
+
+	s := &Schema{}
+	s.Register(&indexable{}, otherIndex(""))
+
+	db, err := Open(dbPath, 0600, nil, s)
+	assert.Nil(t, err)
+	defer func() { assert.Nil(t, db.Close()) }()
+
+	err = db.Update(func(tx *Tx) error {
+		for _, b := range []string{indexBucketName, "otherIndexBucketName"} {
+			if _, err := tx.Tx.Bucket([]byte(b)).CreateBucket([]byte(value1)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	assert.Nil(t, err)
+
+	n, err := db.PruneIndexes()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, n)
+
+	err = db.Update(func(tx *Tx) error {
+		_, err := tx.Tx.Bucket([]byte(indexBucketName)).CreateBucket([]byte(value1))
+		return err
+	})
+	assert.Nil(t, err)
+
+	n, err = db.PruneIndexes(index(""), bucketKey("missing"))
+	assert.EqualError(t, err, "index bucket not found missing")
+	assert.Equal(t, 1, n)
+}
@@ -0,0 +1,56 @@
+package binx
+
+import (
+	"bytes"
+
+	"github.com/pkg/errors"
+)
+
+// Distinct returns the keys of the index bucket of idx, in ascending order,
+// with the number of records held under each. bns narrow the keys down like
+// they do for Scan, and must be on the same index bucket. Only index buckets
+// are read: records missing from the primary bucket are counted, and keys
+// holding no record are left out.
+func (r *Tx) Distinct(idx Bucket, bns ...Bound) ([]KeyCount, error) {
+	if idx == nil {
+		return nil, errors.New(errNilPointer)
+	}
+	if combined(bns) != nil {
+		return nil, errors.New("cannot list distinct keys of combined bounds")
+	}
+	for _, bn := range bns {
+		if !bytes.Equal(bn.BucketKey(), idx.BucketKey()) {
+			return nil, errors.New("cannot list distinct keys of another index")
+		}
+	}
+
+	b, err := parseBounds(bns)
+	if err != nil {
+		return nil, err
+	}
+
+	ix := r.Tx.Bucket(idx.BucketKey())
+	if ix == nil {
+		return nil, ErrIdxNotFound
+	}
+
+	var keys []KeyCount
+	add := func(k []byte) bool {
+		if n := countKeys(ix.Bucket(k), 0); n > 0 {
+			keys = append(keys, KeyCount{Key: copyBytes(k), Count: n})
+		}
+		return true
+	}
+
+	switch {
+	case b.by != nil || len(bns) == 0:
+		walkRange(ix, nil, nil, nil, false, false, add)
+	case b.from == nil && b.to == nil && !b.prefix:
+		add(b.where.Key())
+	default:
+		pk, fk, tk := rangeKeys(b.where, b.from, b.to)
+		walkRange(ix, pk, fk, tk, isExclusive(b.from), isExclusive(b.to), add)
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,112 @@
+package binx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Tx_Distinct(t *testing.T) {
+	db, teardown := prepUsers(t)
+	defer teardown()
+
+	tests := []struct {
+		name     string
+		index    Bucket
+		bounds   []Bound
+		expected []KeyCount
+		err      string
+	}{
+		{
+			name:  "all keys",
+			index: byRole(""),
+			expected: []KeyCount{
+				{[]byte("admin"), 1},
+				{[]byte("dev"), 2},
+			},
+		},
+		{
+			name:     "where",
+			index:    byRole(""),
+			bounds:   []Bound{Where(byRole("dev"))},
+			expected: []KeyCount{{[]byte("dev"), 2}},
+		},
+		{
+			name:   "where missing key",
+			index:  byRole(""),
+			bounds: []Bound{Where(byRole("ops"))},
+		},
+		{
+			name:   "range",
+			index:  byEmail(""),
+			bounds: []Bound{After(byEmail("a@example.com")), Before(byEmail("c@example.com"))},
+			expected: []KeyCount{
+				{[]byte("b@example.com"), 1},
+			},
+		},
+		{
+			name:   "prefix",
+			index:  byEmail(""),
+			bounds: []Bound{Prefix(byEmail("c"))},
+			expected: []KeyCount{
+				{[]byte("c@example.com"), 1},
+			},
+		},
+		{
+			name:   "by",
+			index:  byRole(""),
+			bounds: []Bound{By(byRole(""))},
+			expected: []KeyCount{
+				{[]byte("admin"), 1},
+				{[]byte("dev"), 2},
+			},
+		},
+		{
+			name:   "another index",
+			index:  byRole(""),
+			bounds: []Bound{Where(byEmail("a@example.com"))},
+			err:    "cannot list distinct keys of another index",
+		},
+		{
+			name:   "combined",
+			index:  byRole(""),
+			bounds: []Bound{Not(Where(byRole("dev")))},
+			err:    "cannot list distinct keys of combined bounds",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := db.View(func(tx *Tx) error {
+				keys, err := tx.Distinct(tt.index, tt.bounds...)
+				assert.Equal(t, tt.expected, keys)
+				return err
+			})
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func Test_Tx_Distinct_SkipsPrimaryBucket(t *testing.T) {
+	db, teardown := prepUsers(t)
+	defer teardown()
+
+	err := db.Update(func(tx *Tx) error {
+		return tx.Tx.Bucket([]byte("users")).Delete([]byte(id2))
+	})
+	assert.Nil(t, err)
+
+	err = db.View(func(tx *Tx) error {
+		keys, err := tx.Distinct(byRole(""))
+		assert.Equal(t, []KeyCount{
+			{[]byte("admin"), 1},
+			{[]byte("dev"), 2},
+		}, keys)
+		return err
+	})
+	assert.Nil(t, err)
+}
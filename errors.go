@@ -1,6 +1,9 @@
 package binx
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	ErrNotFound    = errors.New("not found")
@@ -11,3 +14,41 @@ const (
 	errEmptyKey   = "key cannot be empty"
 	errNilPointer = "target must be a pointer to a valid variable"
 )
+
+type ErrUniqueViolation struct {
+	Bucket   []byte
+	Key      []byte
+	Conflict []byte
+}
+
+func (e *ErrUniqueViolation) Error() string {
+	return fmt.Sprintf("unique index %s: key %s is already taken by %s", e.Bucket, e.Key, e.Conflict)
+}
+
+// ErrDanglingIndex is returned by scans meeting an entry of index Bucket
+// under Key whose record PK is missing, see OnDangling. Bucket and Key are
+// nil when the entry was found through And, Or or Not.
+type ErrDanglingIndex struct {
+	Bucket []byte
+	Key    []byte
+	PK     []byte
+}
+
+func (e *ErrDanglingIndex) Error() string {
+	if e.Bucket == nil {
+		return fmt.Sprintf("index entry refers to missing record %s", e.PK)
+	}
+	return fmt.Sprintf("index %s: key %s refers to missing record %s", e.Bucket, e.Key, e.PK)
+}
+
+// ErrReindex is returned by Reindex when indexing a chunk of records fails
+// after the indexes have been cleared. Records up to Last are indexed; Last
+// is empty when none are. Passing Last as ReindexOptions.Resume goes on from
+// there.
+type ErrReindex struct {
+	Last []byte
+	Err  error
+}
+
+func (e *ErrReindex) Error() string { return e.Err.Error() }
+func (e *ErrReindex) Unwrap() error { return e.Err }
@@ -0,0 +1,153 @@
+package binx
+
+import (
+	"bytes"
+	"iter"
+
+	"github.com/pkg/errors"
+)
+
+// Entry is a primary key and its value, as visited by Tx.All.
+type Entry struct {
+	PK    []byte
+	Value []byte
+}
+
+// All returns the entries that Scan with bns would visit in the bucket of b.
+// The query is checked before any entry is visited; this and any error Scan
+// runs into are yielded along with a zero Entry, after which the iteration
+// ends. Index entries whose record is missing end it with ErrDanglingIndex,
+// unless b is a Queryable wrapped with OnDangling or SkipDangling; Desc and
+// Filter wrappers apply as well. Page, Cursor and Count do not apply to a
+// range loop, which stops by itself, and are reported as errors. Entries are
+// only valid while the transaction is open.
+func (r *Tx) All(b Bucket, bns ...Bound) iter.Seq2[Entry, error] {
+	return func(yield func(Entry, error) bool) {
+		if err := checkWrappers(b); err != nil {
+			yield(Entry{}, err)
+			return
+		}
+		if err := r.checkScan(b, bns); err != nil {
+			yield(Entry{}, err)
+			return
+		}
+
+		y := &yielder{Bucket: b, yield: func(pk, v []byte) bool {
+			return yield(Entry{pk, v}, nil)
+		}}
+		if err := r.Scan(y, bns); err != nil {
+			yield(Entry{}, err)
+		}
+	}
+}
+
+// All returns the values that Scan with bns would return, decoded one at a
+// time. Query and decoding errors are yielded along with a zero value, see
+// Tx.All.
+func (c *Collection[T]) All(tx *Tx, bns ...Bound) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+
+		for e, err := range tx.All(c.query(nil), bns...) {
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+
+			var v T
+			if err := c.codec().Unmarshal(e.Value, &v); err != nil {
+				yield(zero, errors.Wrap(err, "unmarshal"))
+				return
+			}
+			if !yield(v, nil) {
+				return
+			}
+		}
+	}
+}
+
+// yielder hands primary keys and values of a scan over to a range loop.
+type yielder struct {
+	Bucket
+	yield    func(pk, v []byte) bool
+	keysOnly bool
+}
+
+func (y *yielder) AppendBinary([]byte) (bool, error) {
+	return false, errors.New("yielder needs the primary key of a value")
+}
+
+// unwrap returns the Bucket of the scan when it is a Queryable, so that the
+// wrappers it may come with apply.
+func (y *yielder) unwrap() Queryable {
+	q, _ := y.Bucket.(Queryable)
+	return q
+}
+
+var errAllWrapper = errors.New("Page, Cursor and Count cannot be used with All")
+
+// checkWrappers reports wrappers of b that All cannot honor.
+func checkWrappers(b Bucket) error {
+	q, _ := b.(Queryable)
+	for q != nil {
+		switch q.(type) {
+		case *page, *Cursor, *Count:
+			return errAllWrapper
+		}
+
+		w, ok := q.(wrapper)
+		if !ok {
+			break
+		}
+		q = w.unwrap()
+	}
+	return nil
+}
+
+// checkScan reports the errors Scan would return for bns before visiting any
+// value.
+func (r *Tx) checkScan(b Bucket, bns []Bound) error {
+	if r.Bucket(b.BucketKey()) == nil {
+		return ErrIdxNotFound
+	}
+
+	if len(bns) == 0 {
+		return nil
+	}
+
+	if c := combined(bns); c != nil {
+		return r.checkCombined(b, c)
+	}
+
+	p, err := parseBounds(bns)
+	if err != nil {
+		return err
+	}
+
+	for _, i := range []Bound{p.where, p.from, p.to, p.by} {
+		if i == nil {
+			continue
+		}
+		if !bytes.Equal(i.BucketKey(), bns[0].BucketKey()) {
+			return errors.New("cannot build range for two different indexes")
+		}
+		if r.Bucket(i.BucketKey()) == nil {
+			return ErrIdxNotFound
+		}
+	}
+
+	return nil
+}
+
+func (r *Tx) checkCombined(b Bucket, bound Bound) error {
+	c, ok := bound.(*combinator)
+	if !ok {
+		return r.checkScan(b, []Bound{bound})
+	}
+	for _, bn := range c.bns {
+		if err := r.checkCombined(b, bn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
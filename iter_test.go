@@ -0,0 +1,200 @@
+package binx
+
+import (
+	"testing"
+
+	bolt "github.com/coreos/bbolt"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_store_All(t *testing.T) {
+	existing := bucket{
+		bucketName: bucket{
+			id1: bt(&indexable{ID: id1, IndexedField: value1}),
+			id2: bt(&indexable{ID: id2, IndexedField: value2}),
+			id3: bt(&indexable{ID: id3, IndexedField: value2}),
+		},
+		indexBucketName: bucket{
+			value1: bucket{id1: []byte{}},
+			// id9 is dangling
+			value2: bucket{id2: []byte{}, id3: []byte{}, "id9": []byte{}},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		bounds        []Bound
+		limit         int
+		expected      []string
+		expectedError string
+	}{
+		{name: "list", expected: []string{id1, id2, id3}},
+		{name: "list break", limit: 2, expected: []string{id1, id2}},
+		{name: "by", bounds: []Bound{By(index(""))}, expected: []string{id1, id2, id3}},
+		{name: "where", bounds: []Bound{Where(index(value2))}, expected: []string{id2, id3}},
+		{name: "range", bounds: []Bound{UpperBound(index(value1))}, expected: []string{id1}},
+		{name: "where break", bounds: []Bound{Where(index(value2))}, limit: 1, expected: []string{id2}},
+		{name: "combined", bounds: []Bound{Not(Where(index(value1)))}, expected: []string{id2, id3}},
+		{
+			name:          "combined missing index bucket",
+			bounds:        []Bound{Or(Where(index(value1)), Where(otherIndex(value1)))},
+			expectedError: ErrIdxNotFound.Error(),
+		},
+		{
+			name:          "missing index bucket",
+			bounds:        []Bound{Where(otherIndex(value1))},
+			expectedError: ErrIdxNotFound.Error(),
+		},
+		{
+			name:          "unsupported bounds",
+			bounds:        []Bound{Where(index(value1)), Where(index(value2))},
+			expectedError: "Not implemented",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, teardown := prep(t, existing)
+			defer teardown()
+
+			err := s.View(func(tx *bolt.Tx) error {
+				var ids []string
+				for e, err := range (&Tx{tx}).All(SkipDangling(&indexableSlice{}), tt.bounds...) {
+					if err != nil {
+						return err
+					}
+					assert.Equal(t, existing[bucketName].(bucket)[string(e.PK)], e.Value)
+					ids = append(ids, string(e.PK))
+					if len(ids) == tt.limit {
+						break
+					}
+				}
+				assert.Equal(t, tt.expected, ids)
+				return nil
+			})
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+				return
+			}
+			assert.Nil(t, err)
+		})
+	}
+}
+
+func Test_store_All_Dangling(t *testing.T) {
+	s, teardown := prep(t, bucket{
+		bucketName: bucket{
+			id1: bt(&indexable{ID: id1, IndexedField: value1}),
+			id3: bt(&indexable{ID: id3, IndexedField: value1}),
+		},
+		indexBucketName: bucket{
+			value1: bucket{id1: []byte{}, id2: []byte{}, id3: []byte{}},
+		},
+	})
+	defer teardown()
+
+	bns := []Bound{Where(index(value1))}
+	err := s.View(func(tx *bolt.Tx) error {
+		var (
+			ids  []string
+			errs []error
+		)
+		for e, err := range (&Tx{tx}).All(indexableSlice{}, bns...) {
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			ids = append(ids, string(e.PK))
+		}
+		assert.Equal(t, []string{id1}, ids)
+		assert.Equal(t, []error{&ErrDanglingIndex{
+			Bucket: []byte(indexBucketName),
+			Key:    []byte(value1),
+			PK:     []byte(id2),
+		}}, errs)
+
+		var dangling []string
+		q := OnDangling(&indexableSlice{}, func(e *ErrDanglingIndex) error {
+			dangling = append(dangling, string(e.PK))
+			return nil
+		})
+		ids = nil
+		for e, err := range (&Tx{tx}).All(Desc(q), bns...) {
+			assert.Nil(t, err)
+			ids = append(ids, string(e.PK))
+		}
+		assert.Equal(t, []string{id3, id1}, ids)
+		assert.Equal(t, []string{id2}, dangling)
+		return nil
+	})
+	assert.Nil(t, err)
+}
+
+func Test_store_All_Wrappers(t *testing.T) {
+	s, teardown := prep(t, bucket{
+		bucketName: bucket{id1: bt(&indexable{ID: id1, IndexedField: value1})},
+	})
+	defer teardown()
+
+	for _, q := range []Queryable{
+		Page(&indexableSlice{}, 0, 1),
+		Desc(&Cursor{Queryable: &indexableSlice{}, Limit: 1}),
+		&Count{Bucket: indexableSlice{}},
+	} {
+		err := s.View(func(tx *bolt.Tx) error {
+			for _, err := range (&Tx{tx}).All(q) {
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		assert.Equal(t, errAllWrapper, err)
+	}
+}
+
+func Test_Collection_All(t *testing.T) {
+	db, teardown := prepUsers(t)
+	defer teardown()
+
+	err := db.View(func(tx *Tx) error {
+		var ids []string
+		for u, err := range users.All(tx, Where(byRole("dev"))) {
+			assert.Nil(t, err)
+			ids = append(ids, u.ID)
+		}
+		assert.Equal(t, []string{id1, id2}, ids)
+
+		for _, err := range users.All(tx, Where(userIndex{"missing", "x"})) {
+			assert.Equal(t, ErrIdxNotFound, err)
+		}
+		return nil
+	})
+	assert.Nil(t, err)
+
+	err = db.Update(func(tx *Tx) error {
+		return tx.Tx.Bucket([]byte("users")).Delete([]byte(id1))
+	})
+	assert.Nil(t, err)
+
+	err = db.View(func(tx *Tx) error {
+		var errs []error
+		for _, err := range users.All(tx, Where(byRole("dev"))) {
+			errs = append(errs, err)
+		}
+		assert.Equal(t, []error{&ErrDanglingIndex{
+			Bucket: []byte("byRole"),
+			Key:    []byte("dev"),
+			PK:     []byte(id1),
+		}}, errs)
+
+		var ids []string
+		skip := users.OnDangling(func(*ErrDanglingIndex) error { return nil })
+		for u, err := range skip.All(tx, Where(byRole("dev"))) {
+			assert.Nil(t, err)
+			ids = append(ids, u.ID)
+		}
+		assert.Equal(t, []string{id2}, ids)
+		return nil
+	})
+	assert.Nil(t, err)
+}
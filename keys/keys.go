@@ -0,0 +1,203 @@
+// Package keys encodes values into index keys whose byte order, as compared
+// by bytes.Compare, matches the natural order of the values. Every Desc
+// variant produces keys in reverse order.
+//
+// Decoders return the bytes left after the decoded value, so several encoded
+// values can be appended one after another and read back in turn.
+package keys
+
+import (
+	"encoding/binary"
+	"math"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	escape     = 0x00
+	escaped    = 0xff
+	terminator = 0x01
+)
+
+var errShortKey = errors.New("key is too short")
+
+func Uint64(v uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	return b
+}
+
+func DecodeUint64(b []byte) (uint64, []byte, error) {
+	if len(b) < 8 {
+		return 0, nil, errShortKey
+	}
+	return binary.BigEndian.Uint64(b), b[8:], nil
+}
+
+func Int64(v int64) []byte {
+	return Uint64(uint64(v) ^ 1<<63)
+}
+
+func DecodeInt64(b []byte) (int64, []byte, error) {
+	u, rest, err := DecodeUint64(b)
+	return int64(u ^ 1<<63), rest, err
+}
+
+func Float64(v float64) []byte {
+	u := math.Float64bits(v)
+	if u&(1<<63) != 0 {
+		u = ^u
+	} else {
+		u |= 1 << 63
+	}
+	return Uint64(u)
+}
+
+func DecodeFloat64(b []byte) (float64, []byte, error) {
+	u, rest, err := DecodeUint64(b)
+	if u&(1<<63) != 0 {
+		u &^= 1 << 63
+	} else {
+		u = ^u
+	}
+	return math.Float64frombits(u), rest, err
+}
+
+// Time encodes t with nanosecond precision. Location is not kept and decoded
+// times are in UTC.
+func Time(t time.Time) []byte {
+	b := make([]byte, 12)
+	copy(b, Int64(t.Unix()))
+	binary.BigEndian.PutUint32(b[8:], uint32(t.Nanosecond()))
+	return b
+}
+
+func DecodeTime(b []byte) (time.Time, []byte, error) {
+	sec, rest, err := DecodeInt64(b)
+	if err != nil {
+		return time.Time{}, nil, err
+	}
+	if len(rest) < 4 {
+		return time.Time{}, nil, errShortKey
+	}
+	return time.Unix(sec, int64(binary.BigEndian.Uint32(rest))).UTC(), rest[4:], nil
+}
+
+// Bytes escapes and terminates v, so shorter values sort before longer ones
+// sharing the same prefix and more keys can follow the encoded value.
+func Bytes(v []byte) []byte {
+	b := make([]byte, 0, len(v)+2)
+	for _, c := range v {
+		b = append(b, c)
+		if c == escape {
+			b = append(b, escaped)
+		}
+	}
+	return append(b, escape, terminator)
+}
+
+func DecodeBytes(b []byte) ([]byte, []byte, error) {
+	v := []byte{}
+	for i := 0; i < len(b); i++ {
+		if b[i] != escape {
+			v = append(v, b[i])
+			continue
+		}
+
+		if i == len(b)-1 {
+			break
+		}
+
+		i++
+		switch b[i] {
+		case escaped:
+			v = append(v, escape)
+		case terminator:
+			return v, b[i+1:], nil
+		default:
+			return nil, nil, errors.Errorf("invalid escape sequence %x", b[i-1:i+1])
+		}
+	}
+	return nil, nil, errors.New("value is not terminated")
+}
+
+func String(v string) []byte {
+	return Bytes([]byte(v))
+}
+
+func DecodeString(b []byte) (string, []byte, error) {
+	v, rest, err := DecodeBytes(b)
+	return string(v), rest, err
+}
+
+func Uint64Desc(v uint64) []byte { return invert(Uint64(v)) }
+
+func DecodeUint64Desc(b []byte) (uint64, []byte, error) {
+	if len(b) < 8 {
+		return 0, nil, errShortKey
+	}
+	v, _, err := DecodeUint64(invert(b[:8]))
+	return v, b[8:], err
+}
+
+func Int64Desc(v int64) []byte { return invert(Int64(v)) }
+
+func DecodeInt64Desc(b []byte) (int64, []byte, error) {
+	if len(b) < 8 {
+		return 0, nil, errShortKey
+	}
+	v, _, err := DecodeInt64(invert(b[:8]))
+	return v, b[8:], err
+}
+
+func Float64Desc(v float64) []byte { return invert(Float64(v)) }
+
+func DecodeFloat64Desc(b []byte) (float64, []byte, error) {
+	if len(b) < 8 {
+		return 0, nil, errShortKey
+	}
+	v, _, err := DecodeFloat64(invert(b[:8]))
+	return v, b[8:], err
+}
+
+func TimeDesc(t time.Time) []byte { return invert(Time(t)) }
+
+func DecodeTimeDesc(b []byte) (time.Time, []byte, error) {
+	if len(b) < 12 {
+		return time.Time{}, nil, errShortKey
+	}
+	v, _, err := DecodeTime(invert(b[:12]))
+	return v, b[12:], err
+}
+
+func BytesDesc(v []byte) []byte { return invert(Bytes(v)) }
+
+func DecodeBytesDesc(b []byte) ([]byte, []byte, error) {
+	for i := 0; i < len(b)-1; i++ {
+		if b[i] != ^byte(escape) {
+			continue
+		}
+		if b[i+1] == ^byte(terminator) {
+			v, _, err := DecodeBytes(invert(b[:i+2]))
+			return v, b[i+2:], err
+		}
+		i++
+	}
+	return nil, nil, errors.New("value is not terminated")
+}
+
+func StringDesc(v string) []byte { return BytesDesc([]byte(v)) }
+
+func DecodeStringDesc(b []byte) (string, []byte, error) {
+	v, rest, err := DecodeBytesDesc(b)
+	return string(v), rest, err
+}
+
+func invert(b []byte) []byte {
+	r := make([]byte, len(b))
+	for i, c := range b {
+		r[i] = ^c
+	}
+	return r
+}
@@ -0,0 +1,93 @@
+package keys
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Order(t *testing.T) {
+	t0 := time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		sorted [][]byte
+	}{
+		{"uint64", [][]byte{Uint64(0), Uint64(1), Uint64(256), Uint64(math.MaxUint64)}},
+		{"int64", [][]byte{Int64(math.MinInt64), Int64(-256), Int64(-1), Int64(0), Int64(1), Int64(math.MaxInt64)}},
+		{"float64", [][]byte{Float64(math.Inf(-1)), Float64(-2.5), Float64(-0.5), Float64(0), Float64(0.5), Float64(2.5), Float64(math.Inf(1))}},
+		{"time", [][]byte{Time(t0.Add(-time.Hour * 24 * 365 * 100)), Time(t0), Time(t0.Add(time.Nanosecond)), Time(t0.Add(time.Second))}},
+		{"string", [][]byte{String(""), String("a"), String("a\x00"), String("a\x00a"), String("ab"), String("b")}},
+		{"uint64 desc", [][]byte{Uint64Desc(math.MaxUint64), Uint64Desc(256), Uint64Desc(1), Uint64Desc(0)}},
+		{"int64 desc", [][]byte{Int64Desc(1), Int64Desc(0), Int64Desc(-1)}},
+		{"float64 desc", [][]byte{Float64Desc(0.5), Float64Desc(0), Float64Desc(-0.5)}},
+		{"time desc", [][]byte{TimeDesc(t0.Add(time.Second)), TimeDesc(t0), TimeDesc(t0.Add(-time.Second))}},
+		{"string desc", [][]byte{StringDesc("b"), StringDesc("ab"), StringDesc("a\x00"), StringDesc("a"), StringDesc("")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 1; i < len(tt.sorted); i++ {
+				assert.Equal(t, -1, bytes.Compare(tt.sorted[i-1], tt.sorted[i]), "key %d", i)
+			}
+		})
+	}
+}
+
+func Test_Decode(t *testing.T) {
+	t0 := time.Date(2020, 5, 1, 10, 30, 0, 42, time.UTC)
+
+	key := bytes.Join([][]byte{
+		Uint64(7), Int64(-7), Float64(-7.5), Time(t0), String("a\x00b"),
+		Uint64Desc(7), Int64Desc(-7), Float64Desc(-7.5), TimeDesc(t0), StringDesc("a\x00b"),
+	}, nil)
+
+	u, key, err := DecodeUint64(key)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(7), u)
+
+	i, key, err := DecodeInt64(key)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(-7), i)
+
+	f, key, err := DecodeFloat64(key)
+	assert.Nil(t, err)
+	assert.Equal(t, -7.5, f)
+
+	tm, key, err := DecodeTime(key)
+	assert.Nil(t, err)
+	assert.Equal(t, t0, tm)
+
+	s, key, err := DecodeString(key)
+	assert.Nil(t, err)
+	assert.Equal(t, "a\x00b", s)
+
+	u, key, err = DecodeUint64Desc(key)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(7), u)
+
+	i, key, err = DecodeInt64Desc(key)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(-7), i)
+
+	f, key, err = DecodeFloat64Desc(key)
+	assert.Nil(t, err)
+	assert.Equal(t, -7.5, f)
+
+	tm, key, err = DecodeTimeDesc(key)
+	assert.Nil(t, err)
+	assert.Equal(t, t0, tm)
+
+	s, key, err = DecodeStringDesc(key)
+	assert.Nil(t, err)
+	assert.Equal(t, "a\x00b", s)
+	assert.Empty(t, key)
+
+	_, _, err = DecodeInt64([]byte{1, 2})
+	assert.NotNil(t, err)
+
+	_, _, err = DecodeString([]byte("abc"))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,505 @@
+package binx
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	bolt "github.com/coreos/bbolt"
+)
+
+// Plan describes how Scan answers a query, see Explain.
+type Plan struct {
+	// Op is "list" for a walk of the primary bucket, "where", "range" or
+	// "by" for a walk of Index, and "and", "or" or "not" for bounds combined
+	// from Inputs.
+	Op    string
+	Index []byte
+	// Seek is the index key the walk starts at, nil when it starts at the
+	// first key in scan order.
+	Seek []byte
+	// Rows estimates the number of records the step yields. It is counted
+	// from the index: where inputs of an and are counted first, side by side,
+	// and counting any input stops once it is known to yield more records
+	// than a sibling. It is -1 when counting was skipped, as it is for steps
+	// whose count cannot change how the scan runs.
+	Rows int
+	// Lookup is set on where steps that check the records found by another
+	// step by looking each of them up in Index, rather than by walking it.
+	Lookup bool
+	// Inputs of and, or and not. An and walks its first input and narrows
+	// the records it yields down with the others, in order.
+	Inputs []*Plan
+	// Desc and Filters are set on the plan of a whole scan and tell whether
+	// it runs backwards and how many Filter predicates values go through.
+	Desc    bool
+	Filters int
+
+	bns []Bound
+}
+
+// Explain returns the plan Scan follows for q and bns, without reading any
+// value.
+func (r *Tx) Explain(q Queryable, bns []Bound) (*Plan, error) {
+	o, err := scanOptions(q)
+	if err != nil {
+		return nil, err
+	}
+
+	var p *Plan
+	if c := combined(bns); c != nil {
+		p, err = planBound(r.Tx, q, c, 0)
+	} else {
+		p, err = planBounds(r.Tx, q, bns, o.desc, 0)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	p.Desc = o.desc
+	p.Filters = len(o.filters)
+	return p, nil
+}
+
+func (p *Plan) String() string {
+	var b strings.Builder
+	p.format(&b, "")
+	return b.String()
+}
+
+func (p *Plan) format(b *strings.Builder, indent string) {
+	b.WriteString(indent + p.Op)
+	if p.Index != nil {
+		fmt.Fprintf(b, " %s", p.Index)
+	}
+	if p.Seek != nil {
+		fmt.Fprintf(b, " seek %q", p.Seek)
+	}
+	if p.Rows < 0 {
+		b.WriteString(" rows=?")
+	} else {
+		fmt.Fprintf(b, " rows=%d", p.Rows)
+	}
+	if p.Lookup {
+		b.WriteString(" lookup")
+	}
+	if p.Desc {
+		b.WriteString(" desc")
+	}
+	if p.Filters > 0 {
+		fmt.Fprintf(b, " filters=%d", p.Filters)
+	}
+	b.WriteString("\n")
+
+	for _, in := range p.Inputs {
+		in.format(b, indent+"  ")
+	}
+}
+
+// noCount makes planning leave Rows out. Scan plans with it, as it only
+// needs the row counts that order the inputs of an and.
+const noCount = -1
+
+// planBound plans the bound of a combined scan of the bucket of q. Counting
+// rows stops past limit, unless it is zero, and is skipped when it is
+// noCount.
+func planBound(r *bolt.Tx, q Bucket, bound Bound, limit int) (*Plan, error) {
+	c, ok := bound.(*combinator)
+	if !ok {
+		return planBounds(r, q, []Bound{bound}, false, limit)
+	}
+
+	switch c.op {
+	case opOr:
+		p := &Plan{Op: "or", Rows: noCount}
+		for _, bn := range c.bns {
+			l := limit
+			switch {
+			case limit > 0 && p.Rows > limit:
+				l = noCount
+			case limit > 0:
+				l = max(limit-max(p.Rows, 0), 1)
+			}
+
+			in, err := planBound(r, q, bn, l)
+			if err != nil {
+				return nil, err
+			}
+			p.Inputs = append(p.Inputs, in)
+			if in.Rows >= 0 {
+				p.Rows = max(p.Rows, 0) + in.Rows
+			}
+		}
+		if limit == noCount {
+			p.Rows = noCount
+		}
+		return p, nil
+	case opNot:
+		return planNot(r, q, c.bns[0], limit)
+	}
+
+	// where inputs are counted first, side by side from a single nested
+	// bucket each, so that counting stops past the fewest records they hold.
+	var (
+		wheres, others []Bound
+		nots           []Bound
+	)
+	for _, bn := range c.bns {
+		if n, ok := bn.(*combinator); ok && n.op == opNot {
+			nots = append(nots, n.bns...)
+			continue
+		}
+		if isWhere(bn) {
+			wheres = append(wheres, bn)
+		} else {
+			others = append(others, bn)
+		}
+	}
+
+	var (
+		inputs []*Plan
+		own    = max(limit, 0)
+	)
+	for _, bn := range wheres {
+		in, err := planBound(r, q, bn, noCount)
+		if err != nil {
+			return nil, err
+		}
+		inputs = append(inputs, in)
+	}
+	if len(inputs) > 0 {
+		countWheres(r, inputs, own)
+		own = inputs[0].Rows
+		for _, in := range inputs[1:] {
+			own = min(own, in.Rows)
+		}
+	}
+
+	for _, bn := range others {
+		l := own
+		if len(inputs) > 0 && own == 0 {
+			// an input holds no record, and neither does the and
+			l = noCount
+		}
+
+		in, err := planBound(r, q, bn, l)
+		if err != nil {
+			return nil, err
+		}
+		inputs = append(inputs, in)
+		if l != noCount && (own == 0 || in.Rows < own) {
+			own = in.Rows
+		}
+	}
+	sort.SliceStable(inputs, func(i, j int) bool { return rank(inputs[i]) < rank(inputs[j]) })
+
+	if len(inputs) == 0 {
+		all, err := planBounds(r, q, nil, false, limit)
+		if err != nil {
+			return nil, err
+		}
+		inputs = append(inputs, all)
+		own = all.Rows
+	}
+
+	for _, in := range inputs[1:] {
+		in.Lookup = in.Op == "where"
+	}
+
+	if limit != noCount {
+		limit = own
+	}
+	for _, bn := range nots {
+		in, err := planNot(r, q, bn, limit)
+		if err != nil {
+			return nil, err
+		}
+		inputs = append(inputs, in)
+	}
+
+	return &Plan{Op: "and", Rows: inputs[0].Rows, Inputs: inputs}, nil
+}
+
+// rank orders the inputs of an and, the ones that were not counted last.
+func rank(p *Plan) int {
+	if p.Rows < 0 {
+		return math.MaxInt
+	}
+	return p.Rows
+}
+
+// isWhere tells whether bn is planned as a where step.
+func isWhere(bn Bound) bool {
+	switch bn.(type) {
+	case *combinator, prefix:
+		return false
+	}
+	return bn.Upper() && bn.Lower()
+}
+
+func planNot(r *bolt.Tx, q Bucket, bound Bound, limit int) (*Plan, error) {
+	in, err := planBound(r, q, bound, limit)
+	if err != nil {
+		return nil, err
+	}
+	bkt := r.Bucket(q.BucketKey())
+	if bkt == nil {
+		return nil, ErrIdxNotFound
+	}
+
+	in.Lookup = in.Op == "where"
+
+	p := &Plan{Op: "not", Rows: noCount, Inputs: []*Plan{in}}
+	if limit != noCount {
+		p.Rows = max(bkt.Stats().KeyN-in.Rows, 0)
+	}
+	return p, nil
+}
+
+// planBounds plans an ordinary scan of the bucket of q with bns, see Scan.
+func planBounds(r *bolt.Tx, q Bucket, bns []Bound, desc bool, limit int) (*Plan, error) {
+	if len(bns) == 0 {
+		bkt := r.Bucket(q.BucketKey())
+		if bkt == nil {
+			return nil, ErrIdxNotFound
+		}
+		p := &Plan{Op: "list", Rows: noCount}
+		if limit != noCount {
+			p.Rows = bkt.Stats().KeyN
+		}
+		return p, nil
+	}
+
+	b, err := parseBounds(bns)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		p     = &Plan{bns: bns}
+		index Index
+	)
+	switch {
+	case b.by != nil:
+		p.Op, index = "by", b.by
+	case b.from == nil && b.to == nil && !b.prefix:
+		p.Op, index, p.Seek = "where", b.where, b.where.Key()
+	default:
+		p.Op = "range"
+		for _, i := range []Index{b.where, b.from, b.to} {
+			if i != nil {
+				index = i
+				break
+			}
+		}
+	}
+	p.Index = index.BucketKey()
+
+	ix := r.Bucket(p.Index)
+	if ix == nil {
+		return nil, ErrIdxNotFound
+	}
+
+	switch {
+	case limit == noCount:
+		p.Rows = noCount
+		if p.Op == "range" {
+			p.Seek = rangeSeek(b, desc)
+		}
+	case p.Op == "by":
+		p.Rows = countRange(ix, nil, nil, nil, false, false, limit)
+	case p.Op == "where":
+		p.Rows = countKeys(ix.Bucket(p.Seek), limit)
+	default:
+		pk, fk, tk := rangeKeys(b.where, b.from, b.to)
+		p.Seek = rangeSeek(b, desc)
+		p.Rows = countRange(ix, pk, fk, tk, isExclusive(b.from), isExclusive(b.to), limit)
+	}
+
+	return p, nil
+}
+
+// rangeSeek returns the index key a range walk starts at, nil when it starts
+// at the first key in scan order.
+func rangeSeek(b bounds, desc bool) []byte {
+	pk, fk, tk := rangeKeys(b.where, b.from, b.to)
+	switch {
+	case !desc && fk != nil:
+		return fk
+	case !desc && len(pk) > 0:
+		return pk
+	case tk != nil:
+		return tk
+	}
+	return nil
+}
+
+// countRange counts the primary keys held under the index keys of ix that
+// start with pk and lie between fk and tk. It stops once the count is past
+// limit, unless limit is zero.
+func countRange(ix *bolt.Bucket, pk, fk, tk []byte, fromExcl, toExcl bool, limit int) int {
+	n := 0
+	walkRange(ix, pk, fk, tk, fromExcl, toExcl, func(k []byte) bool {
+		n += countKeys(ix.Bucket(k), limit)
+		return limit == 0 || n <= limit
+	})
+	return n
+}
+
+// walkRange calls fn with the index keys of ix that start with pk and lie
+// between fk and tk, in ascending order, until it returns false.
+func walkRange(ix *bolt.Bucket, pk, fk, tk []byte, fromExcl, toExcl bool, fn func(k []byte) bool) {
+	cur := ix.Cursor()
+
+	var k []byte
+	switch {
+	case fk != nil:
+		k, _ = cur.Seek(fk)
+	case pk != nil:
+		k, _ = cur.Seek(pk)
+	default:
+		k, _ = cur.First()
+	}
+
+	for ; k != nil; k, _ = cur.Next() {
+		if !bytes.HasPrefix(k, pk) {
+			break
+		}
+		if fromExcl && bytes.Equal(k, fk) {
+			continue
+		}
+		if tk != nil {
+			if c := bytes.Compare(k, tk); c > 0 || c == 0 && toExcl {
+				break
+			}
+		}
+
+		if !fn(k) {
+			break
+		}
+	}
+}
+
+// countKeys counts the keys of b, walking them with a cursor. It stops once
+// the count is past limit, unless limit is zero.
+func countKeys(b *bolt.Bucket, limit int) int {
+	if b == nil {
+		return 0
+	}
+
+	n := 0
+	c := b.Cursor()
+	for k, _ := c.First(); k != nil && (limit == 0 || n <= limit); k, _ = c.Next() {
+		n++
+	}
+	return n
+}
+
+// countWheres counts the primary keys the where plans ps yield, walking
+// their nested buckets side by side. It stops once one of them is fully
+// counted, so the others are counted one past it at most, or once all of
+// them are past limit, unless it is zero.
+func countWheres(r *bolt.Tx, ps []*Plan, limit int) {
+	curs := make([]*bolt.Cursor, len(ps))
+	for i, p := range ps {
+		p.Rows = 0
+		if b := r.Bucket(p.Index).Bucket(p.Seek); b != nil {
+			curs[i] = b.Cursor()
+		}
+	}
+
+	for done := false; !done; {
+		for i, c := range curs {
+			var k []byte
+			switch {
+			case c == nil:
+			case ps[i].Rows == 0:
+				k, _ = c.First()
+			default:
+				k, _ = c.Next()
+			}
+			if k == nil {
+				done = true
+				curs[i] = nil
+				continue
+			}
+			ps[i].Rows++
+			if limit > 0 && ps[i].Rows > limit {
+				curs[i] = nil
+			}
+		}
+	}
+}
+
+// keys returns the primary keys of the bucket of q the plan yields, without
+// reading any value.
+func (p *Plan) keys(r *bolt.Tx, q Bucket) (map[string]bool, error) {
+	switch p.Op {
+	case "or":
+		set := map[string]bool{}
+		for _, in := range p.Inputs {
+			s, err := in.keys(r, q)
+			if err != nil {
+				return nil, err
+			}
+			for k := range s {
+				set[k] = true
+			}
+		}
+		return set, nil
+	case "not":
+		set, err := scanKeys(r, q, nil)
+		if err != nil {
+			return nil, err
+		}
+		return p.Inputs[0].narrow(r, q, set, false)
+	case "and":
+		set, err := p.Inputs[0].keys(r, q)
+		if err != nil {
+			return nil, err
+		}
+		for _, in := range p.Inputs[1:] {
+			if len(set) == 0 {
+				break
+			}
+			if in.Op == "not" {
+				set, err = in.Inputs[0].narrow(r, q, set, false)
+			} else {
+				set, err = in.narrow(r, q, set, true)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		return set, nil
+	}
+
+	return scanKeys(r, q, p.bns)
+}
+
+// narrow keeps the keys of set the plan yields, or the ones it does not yield
+// when keep is false.
+func (p *Plan) narrow(r *bolt.Tx, q Bucket, set map[string]bool, keep bool) (map[string]bool, error) {
+	if p.Op == "where" {
+		kb := r.Bucket(p.Index).Bucket(p.Seek)
+		for k := range set {
+			if (kb != nil && hasKey(kb, []byte(k))) != keep {
+				delete(set, k)
+			}
+		}
+		return set, nil
+	}
+
+	s, err := p.keys(r, q)
+	if err != nil {
+		return nil, err
+	}
+	for k := range set {
+		if s[k] != keep {
+			delete(set, k)
+		}
+	}
+	return set, nil
+}
@@ -0,0 +1,174 @@
+package binx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Tx_Explain(t *testing.T) {
+	db, teardown := prepUsers(t)
+	defer teardown()
+
+	tests := []struct {
+		name     string
+		bounds   []Bound
+		argument func(q Queryable) Queryable
+		expected string
+	}{
+		{
+			name:     "list",
+			expected: "list rows=3\n",
+		},
+		{
+			name:     "where",
+			bounds:   []Bound{Where(byRole("dev"))},
+			expected: "where byRole seek \"dev\" rows=2\n",
+		},
+		{
+			name:     "by",
+			bounds:   []Bound{By(byRole(""))},
+			expected: "by byRole rows=3\n",
+		},
+		{
+			name:   "range desc with filter",
+			bounds: []Bound{After(byEmail("a@example.com")), Before(byEmail("c@example.com"))},
+			argument: func(q Queryable) Queryable {
+				return Desc(Filter(q, func(_, _ []byte) bool { return true }))
+			},
+			expected: "range byEmail seek \"c@example.com\" rows=1 desc filters=1\n",
+		},
+		{
+			name:   "and driven by the most selective index",
+			bounds: []Bound{And(Where(byRole("dev")), Where(byEmail("a@example.com")))},
+			expected: "and rows=1\n" +
+				"  where byEmail seek \"a@example.com\" rows=1\n" +
+				"  where byRole seek \"dev\" rows=2 lookup\n",
+		},
+		{
+			name:   "and with range and not",
+			bounds: []Bound{Prefix(byEmail("")), Not(Where(byRole("admin")))},
+			expected: "and rows=3\n" +
+				"  range byEmail rows=3\n" +
+				"  not rows=2\n" +
+				"    where byRole seek \"admin\" rows=1 lookup\n",
+		},
+		{
+			name:   "or",
+			bounds: []Bound{Or(Where(byRole("admin")), LowerBound(byEmail("b")))},
+			expected: "or rows=3\n" +
+				"  where byRole seek \"admin\" rows=1\n" +
+				"  range byEmail seek \"b\" rows=2\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var vs []user
+			q := users.Slice(&vs)
+			if tt.argument != nil {
+				q = tt.argument(q)
+			}
+
+			err := db.View(func(tx *Tx) error {
+				p, err := tx.Explain(q, tt.bounds)
+				if err != nil {
+					return err
+				}
+				assert.Equal(t, tt.expected, p.String())
+				return nil
+			})
+			assert.Nil(t, err)
+		})
+	}
+}
+
+func Test_Tx_Explain_CountStopsPastSibling(t *testing.T) {
+	db, teardown := prepUsers(t)
+	defer teardown()
+
+	err := db.View(func(tx *Tx) error {
+		p, err := tx.Explain(users.Slice(nil), []Bound{And(Where(byRole("admin")), By(byEmail("")))})
+		if err != nil {
+			return err
+		}
+		assert.Equal(t, 1, p.Inputs[0].Rows)
+		assert.Equal(t, "by", p.Inputs[1].Op)
+		assert.Equal(t, 2, p.Inputs[1].Rows)
+		return nil
+	})
+	assert.Nil(t, err)
+}
+
+func Test_Tx_Explain_WhereCountedSideBySide(t *testing.T) {
+	db, teardown := prepUsers(t)
+	defer teardown()
+
+	err := db.Update(func(tx *Tx) error {
+		for _, id := range []string{"id4", "id5", "id6"} {
+			if err := users.Put(tx, user{ID: id, Email: id + "@example.com", Roles: []string{"dev"}}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	assert.Nil(t, err)
+
+	err = db.View(func(tx *Tx) error {
+		p, err := tx.Explain(users.Slice(nil), []Bound{And(Where(byRole("dev")), Where(byRole("admin")))})
+		if err != nil {
+			return err
+		}
+		assert.Equal(t, "and rows=1\n"+
+			"  where byRole seek \"admin\" rows=1\n"+
+			"  where byRole seek \"dev\" rows=2 lookup\n", p.String())
+		return nil
+	})
+	assert.Nil(t, err)
+}
+
+func Test_Tx_Explain_WhereCountedFirst(t *testing.T) {
+	db, teardown := prepUsers(t)
+	defer teardown()
+
+	err := db.View(func(tx *Tx) error {
+		p, err := tx.Explain(users.Slice(nil), []Bound{
+			And(By(byEmail("")), Or(Prefix(byEmail("")), Where(byRole("dev"))), Where(byRole("admin"))),
+		})
+		if err != nil {
+			return err
+		}
+		assert.Equal(t, "and rows=1\n"+
+			"  where byRole seek \"admin\" rows=1\n"+
+			"  by byEmail rows=2\n"+
+			"  or rows=2\n"+
+			"    range byEmail rows=2\n"+
+			"    where byRole seek \"dev\" rows=?\n", p.String())
+		return nil
+	})
+	assert.Nil(t, err)
+}
+
+func Test_planBound_ScanSkipsCounting(t *testing.T) {
+	db, teardown := prepUsers(t)
+	defer teardown()
+
+	err := db.View(func(tx *Tx) error {
+		p, err := planBound(tx.Tx, users, Or(
+			Where(byRole("dev")),
+			Not(Where(byRole("admin"))),
+			And(Where(byRole("dev")), By(byEmail(""))),
+		), noCount)
+		if err != nil {
+			return err
+		}
+		assert.Equal(t, "or rows=?\n"+
+			"  where byRole seek \"dev\" rows=?\n"+
+			"  not rows=?\n"+
+			"    where byRole seek \"admin\" rows=? lookup\n"+
+			"  and rows=2\n"+
+			"    where byRole seek \"dev\" rows=2\n"+
+			"    by byEmail rows=3\n", p.String())
+		return nil
+	})
+	assert.Nil(t, err)
+}
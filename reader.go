@@ -17,7 +17,7 @@ func (r *Tx) Get(q Queryable, key []byte) error {
 	}
 	bkt := tx.Bucket(q.BucketKey())
 	if bkt == nil {
-		panic("cannot get bkt " + string(q.BucketKey()))
+		return errors.New("cannot get bucket " + string(q.BucketKey()))
 	}
 	data := bkt.Get(key)
 	if data == nil {
@@ -31,70 +31,200 @@ func (r *Tx) Get(q Queryable, key []byte) error {
 
 func (r *Tx) Scan(s Queryable, bns []Bound) error {
 	tx := r.Tx
+	o, err := scanOptions(s)
+	if err != nil {
+		return err
+	}
 
 	if len(bns) == 0 {
-		return list(tx, s)
+		return list(tx, s, o)
+	}
+
+	if c := combined(bns); c != nil {
+		return listCombined(tx, s, c, o)
+	}
+
+	b, err := parseBounds(bns)
+	if err != nil {
+		return err
+	}
+
+	if o.resume != nil && len(o.resume) != 2 {
+		return errInvalidToken
 	}
 
-	v := bns
+	switch {
+	case b.by != nil:
+		return listBy(tx, s, b.by, o)
+	case b.from == nil && b.to == nil && !b.prefix:
+		return listWhere(tx, s, b.where, o)
+	default:
+		return listRange(tx, s, b.where, b.from, b.to, o)
+	}
+}
 
-	if len(v) == 1 {
+type options struct {
+	desc     bool
+	cursor   *Cursor
+	resume   [][]byte
+	keysOnly bool
+	dangling func(*ErrDanglingIndex) error
+	filters  []func(pk, v []byte) (bool, error)
+}
 
-		b := v[0]
+var errInvalidToken = errors.New("invalid cursor token")
+
+func scanOptions(q Queryable) (o options, err error) {
+	for q != nil {
+		switch v := q.(type) {
+		case *desc:
+			o.desc = true
+		case *Cursor:
+			o.cursor = v
+			v.n, v.pos = 0, nil
+		case *Count:
+			o.keysOnly = true
+		case *filter:
+			o.filters = append(o.filters, v.fn)
+		case *dangling:
+			if o.dangling == nil {
+				o.dangling = v.fn
+			}
+		case *yielder:
+			o.keysOnly = v.keysOnly
+		}
 
-		if b.Upper() && b.Lower() {
-			return listWhere(tx, s, b)
+		w, ok := q.(wrapper)
+		if !ok {
+			break
 		}
+		q = w.unwrap()
+	}
 
-		if !b.Upper() && !b.Lower() {
-			return listBy(tx, s, v[0])
+	if len(o.filters) > 0 {
+		o.keysOnly = false
+	}
+
+	if o.cursor != nil && len(o.cursor.Token) > 0 {
+		o.resume, err = SplitTuple(o.cursor.Token)
+		if err != nil {
+			return o, errInvalidToken
 		}
+	}
+	return o, nil
+}
 
-		if b.Upper() {
-			return listRange(tx, s, nil, b)
+// append hands a value over to q unless a filter leaves it out, keeping
+// track of the position it was found at. ik is nil when the primary bucket
+// is scanned.
+func (o options) append(q Queryable, ik, pk, v []byte) (bool, error) {
+	for _, f := range o.filters {
+		ok, err := f(pk, v)
+		if err != nil {
+			return false, err
+		}
+		if !ok {
+			return true, nil
 		}
+	}
 
-		if b.Lower() {
-			return listRange(tx, s, b, nil)
+	if o.cursor != nil {
+		if ik == nil {
+			o.cursor.pos = Tuple(pk)
+		} else {
+			o.cursor.pos = Tuple(ik, pk)
 		}
+	}
+	if y, ok := q.(*yielder); ok {
+		return y.yield(pk, v), nil
+	}
+	return q.AppendBinary(v)
+}
 
+// skipDangling applies the dangling entry policy of the scan to the entry of
+// record pk under index key ik of index bucket ib. It returns nil when the
+// entry is to be skipped.
+func (o options) skipDangling(ib, ik, pk []byte) error {
+	err := &ErrDanglingIndex{
+		Bucket: copyBytes(ib),
+		Key:    copyBytes(ik),
+		PK:     copyBytes(pk),
 	}
+	if o.dangling == nil {
+		return err
+	}
+	return o.dangling(err)
+}
 
-	if len(v) == 2 {
+type bounds struct {
+	where, from, to, by Bound
+	prefix              bool
+}
 
-		if v[0].Upper() && v[1].Lower() {
-			return listRange(tx, s, v[1], v[0])
+func parseBounds(bns []Bound) (b bounds, err error) {
+	notImplemented := errors.New("Not implemented")
+
+	for _, v := range bns {
+		var dst *Bound
+		switch {
+		case v.Upper() && v.Lower():
+			dst = &b.where
+		case v.Lower():
+			dst = &b.from
+		case v.Upper():
+			dst = &b.to
+		default:
+			dst = &b.by
 		}
-		if v[1].Upper() && v[0].Lower() {
-			return listRange(tx, s, v[0], v[1])
+
+		if *dst != nil {
+			return b, notImplemented
 		}
+		*dst = v
+	}
+
+	if _, ok := b.where.(prefix); ok {
+		b.prefix = true
 	}
 
-	return errors.New("Not implemented")
+	if b.by != nil && len(bns) > 1 {
+		return b, notImplemented
+	}
+
+	return b, nil
 }
 
-func list(r *bolt.Tx, q Queryable) error {
+func list(r *bolt.Tx, q Queryable, o options) error {
 	bkt := r.Bucket(q.BucketKey())
 	if bkt == nil {
 		return ErrIdxNotFound
 	}
 
-	c := bkt.Cursor()
-	for k, v := c.First(); k != nil; k, v = c.Next() {
-		more, err := q.AppendBinary(v)
-		if !more {
-			return nil
-		}
+	if o.resume != nil && len(o.resume) != 1 {
+		return errInvalidToken
+	}
+
+	c := direction{bkt.Cursor(), o.desc}
+	k, v := c.first()
+	if o.resume != nil {
+		k, v = c.seekAfter(o.resume[0])
+	}
 
+	for ; k != nil; k, v = c.next() {
+		more, err := o.append(q, nil, k, v)
 		if err != nil {
 			return errors.Wrap(err, "failed to unmarshal storable")
 		}
+
+		if !more {
+			return nil
+		}
 	}
 
 	return nil
 }
 
-func listBy(r *bolt.Tx, q Queryable, byIdx Bucket) error {
+func listBy(r *bolt.Tx, q Queryable, byIdx Bucket, o options) error {
 	bkt := r.Bucket(q.BucketKey())
 	if bkt == nil {
 		return ErrIdxNotFound
@@ -105,34 +235,34 @@ func listBy(r *bolt.Tx, q Queryable, byIdx Bucket) error {
 		return ErrIdxNotFound
 	}
 
-	ic := ix.Cursor()
-
-	more := false
+	ic := direction{ix.Cursor(), o.desc}
 
-	for ik, _ := ic.First(); ik != nil; ik, _ = ic.Next() {
-
-		kb := ix.Bucket(ik)
-		kc := kb.Cursor()
+	ik, _ := ic.first()
+	if o.resume != nil {
+		ik, _ = ic.seek(o.resume[0])
+	}
 
-		for k, _ := kc.First(); k != nil; k, _ = kc.Next() {
-			var err error = nil
-			more, err = q.AppendBinary(bkt.Get(k))
-			if err != nil {
-				return err
-			}
-			if !more {
-				return nil
-			}
+	for ; ik != nil; ik, _ = ic.next() {
+		more, err := listIndexed(bkt, ix.Bucket(ik), byIdx.BucketKey(), ik, q, o)
+		if err != nil || !more {
+			return err
 		}
-
 	}
 
 	return nil
 }
 
-func listRange(r *bolt.Tx, q Queryable, from, to Index) error {
+// listRange walks index keys between from and to, which are inclusive unless
+// they are After or Before bounds. When prefix is set only keys starting with
+// it are visited and the keys of from and to are appended to it, which is how
+// a compound Where narrows a range.
+func listRange(r *bolt.Tx, q Queryable, prefix, from, to Index, o options) error {
+
+	index := prefix
 
-	index := from
+	if index == nil {
+		index = from
+	}
 
 	if index == nil {
 		index = to
@@ -142,13 +272,8 @@ func listRange(r *bolt.Tx, q Queryable, from, to Index) error {
 		return errors.New("cannot build range with nil index")
 	}
 
-	if to != nil {
-		if !bytes.Equal(index.BucketKey(), to.BucketKey()) {
-			return errors.New("cannot build range for two different indexes")
-		}
-	}
-	if from != nil {
-		if !bytes.Equal(index.BucketKey(), from.BucketKey()) {
+	for _, i := range []Index{from, to} {
+		if i != nil && !bytes.Equal(index.BucketKey(), i.BucketKey()) {
 			return errors.New("cannot build range for two different indexes")
 		}
 	}
@@ -166,36 +291,76 @@ func listRange(r *bolt.Tx, q Queryable, from, to Index) error {
 		return ErrIdxNotFound
 	}
 
-	ic := ix.Cursor()
+	pk, fk, tk := rangeKeys(prefix, from, to)
+
+	ic := direction{ix.Cursor(), o.desc}
+
+	var s []byte
+	switch {
+	case !o.desc && fk != nil:
+		s, _ = ic.Seek(fk)
+	case !o.desc && pk != nil:
+		s, _ = ic.Seek(pk)
+	case o.desc && tk != nil:
+		s, _ = ic.seek(tk)
+	case o.desc && pk != nil:
+		s, _ = ic.seekBefore(prefixEnd(pk))
+	default:
+		s, _ = ic.first()
+	}
 
-	s, _ := ic.First()
-	if from != nil {
-		s, _ = ic.Seek(from.Key())
+	if o.resume != nil {
+		s, _ = ic.seek(o.resume[0])
 	}
 
-	for ik := s; ik != nil; ik, _ = ic.Next() {
-		if to != nil && bytes.Compare(ik, to.Key()) > 0 {
+	fromExcl, toExcl := isExclusive(from), isExclusive(to)
+
+	for ik := s; ik != nil; ik, _ = ic.next() {
+		if !bytes.HasPrefix(ik, pk) {
 			break
 		}
-
-		kb := ix.Bucket(ik)
-		kc := kb.Cursor()
-
-		for k, _ := kc.First(); k != nil; k, _ = kc.Next() {
-			more, err := q.AppendBinary(bkt.Get(k))
-			if err != nil {
-				return err
+		if fk != nil {
+			if c := bytes.Compare(ik, fk); c < 0 || c == 0 && fromExcl {
+				if o.desc {
+					break
+				}
+				continue
 			}
-			if !more {
-				return nil
+		}
+		if tk != nil {
+			if c := bytes.Compare(ik, tk); c > 0 || c == 0 && toExcl {
+				if !o.desc {
+					break
+				}
+				continue
 			}
 		}
+
+		more, err := listIndexed(bkt, ix.Bucket(ik), index.BucketKey(), ik, q, o)
+		if err != nil || !more {
+			return err
+		}
 	}
 
 	return nil
 }
 
-func listWhere(r *bolt.Tx, q Queryable, index Index) error {
+// rangeKeys returns the key every index key of a range starts with, and the
+// keys of from and to appended to it.
+func rangeKeys(prefix, from, to Index) (pk, fk, tk []byte) {
+	if prefix != nil {
+		pk = prefix.Key()
+	}
+	if from != nil {
+		fk = append(append([]byte{}, pk...), from.Key()...)
+	}
+	if to != nil {
+		tk = append(append([]byte{}, pk...), to.Key()...)
+	}
+	return pk, fk, tk
+}
+
+func listWhere(r *bolt.Tx, q Queryable, index Index, o options) error {
 	if q == nil {
 		return errors.New(errNilPointer)
 	}
@@ -214,17 +379,108 @@ func listWhere(r *bolt.Tx, q Queryable, index Index) error {
 		return nil
 	}
 
-	c := ik.Cursor()
-	for k, _ := c.First(); k != nil; k, _ = c.Next() {
+	_, err := listIndexed(bkt, ik, index.BucketKey(), index.Key(), q, o)
+	return err
+}
+
+func isExclusive(i Index) bool {
+	e, ok := i.(exclusive)
+	return ok && e.exclusive()
+}
 
-		more, err := q.AppendBinary(bkt.Get(k))
-		if err != nil {
-			return err
+// listIndexed appends values of primary keys held by the nested bucket kb of
+// index key ik in index bucket ib. It reports whether the scan should go on.
+func listIndexed(bkt, kb *bolt.Bucket, ib, ik []byte, q Queryable, o options) (bool, error) {
+	kc := direction{kb.Cursor(), o.desc}
+
+	k, _ := kc.first()
+	if o.resume != nil && bytes.Equal(ik, o.resume[0]) {
+		k, _ = kc.seekAfter(o.resume[1])
+	}
+
+	for ; k != nil; k, _ = kc.next() {
+		var v []byte
+		if !o.keysOnly {
+			if v = bkt.Get(k); v == nil {
+				if err := o.skipDangling(ib, ik, k); err != nil {
+					return false, err
+				}
+				continue
+			}
 		}
-		if !more {
-			return nil
+
+		more, err := o.append(q, ik, k, v)
+		if err != nil || !more {
+			return false, err
 		}
 	}
 
+	return true, nil
+}
+
+// direction walks a cursor forward, or backward for Desc scans.
+type direction struct {
+	*bolt.Cursor
+	desc bool
+}
+
+func (d direction) first() ([]byte, []byte) {
+	if d.desc {
+		return d.Last()
+	}
+	return d.First()
+}
+
+func (d direction) next() ([]byte, []byte) {
+	if d.desc {
+		return d.Prev()
+	}
+	return d.Next()
+}
+
+// seek moves to key or, when it is missing, to the closest key that comes
+// after it in scan order.
+func (d direction) seek(key []byte) ([]byte, []byte) {
+	k, v := d.Seek(key)
+	if !d.desc || bytes.Equal(k, key) {
+		return k, v
+	}
+	if k == nil {
+		return d.Last()
+	}
+	return d.Prev()
+}
+
+// seekAfter moves to the first key that comes after key in scan order.
+func (d direction) seekAfter(key []byte) ([]byte, []byte) {
+	k, v := d.seek(key)
+	if bytes.Equal(k, key) {
+		return d.next()
+	}
+	return k, v
+}
+
+// seekBefore moves to the last key lower than key, or to the last key at all
+// when key is nil.
+func (d direction) seekBefore(key []byte) ([]byte, []byte) {
+	if key == nil {
+		return d.Last()
+	}
+	if k, _ := d.Seek(key); k == nil {
+		return d.Last()
+	}
+	return d.Prev()
+}
+
+// prefixEnd returns the lowest key greater than every key starting with
+// prefix, or nil when there is none.
+func prefixEnd(prefix []byte) []byte {
+	end := append([]byte{}, prefix...)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
 	return nil
 }
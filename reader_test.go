@@ -1,9 +1,12 @@
 package binx
 
 import (
+	"bytes"
 	"testing"
 
 	bolt "github.com/coreos/bbolt"
+	"github.com/gimalay/binx/keys"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -63,6 +66,13 @@ func Test_store_Get(t *testing.T) {
 			argument:      &indexable{},
 			expectedError: ErrNotFound.Error(),
 		},
+		{
+			name:          "bucket not found",
+			existing:      bucket{},
+			keyToGet:      []byte(id1),
+			argument:      &indexable{},
+			expectedError: "cannot get bucket " + bucketName,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -459,3 +469,734 @@ func Test_store_List(t *testing.T) {
 		})
 	}
 }
+
+func Test_store_ListCompound(t *testing.T) {
+	key := func(tenant, created string) string { return string(Tuple([]byte(tenant), []byte(created))) }
+	compound := func(parts ...string) Index {
+		bs := make([][]byte, 0, len(parts))
+		for _, p := range parts {
+			bs = append(bs, []byte(p))
+		}
+		return Compound(index(""), bs...)
+	}
+
+	existing := bucket{
+		bucketName: bucket{
+			id1: bt(&indexable{ID: id1, IndexedField: value1}),
+			id2: bt(&indexable{ID: id2, IndexedField: value2}),
+			id3: bt(&indexable{ID: id3, IndexedField: value3}),
+		},
+		indexBucketName: bucket{
+			key("a", "2020-05-01"):  bucket{id1: []byte{}},
+			key("a", "2020-05-02"):  bucket{id2: []byte{}},
+			key("a", "2020-05-03"):  bucket{id3: []byte{}},
+			key("b", "2020-05-02"):  bucket{id1: []byte{}},
+			key("ab", "2020-05-02"): bucket{id3: []byte{}},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		bounds   []Bound
+		desc     bool
+		expected indexableSlice
+	}{
+		{
+			name:   "where with lower bound",
+			bounds: []Bound{Where(compound("a")), LowerBound(compound("2020-05-02"))},
+			expected: indexableSlice{
+				indexable{ID: id2, IndexedField: value2},
+				indexable{ID: id3, IndexedField: value3},
+			},
+		},
+		{
+			name:   "where with upper bound",
+			bounds: []Bound{UpperBound(compound("2020-05-02")), Where(compound("a"))},
+			expected: indexableSlice{
+				indexable{ID: id1, IndexedField: value1},
+				indexable{ID: id2, IndexedField: value2},
+			},
+		},
+		{
+			name: "where with both bounds",
+			bounds: []Bound{
+				Where(compound("a")),
+				LowerBound(compound("2020-05-02")),
+				UpperBound(compound("2020-05-02")),
+			},
+			expected: indexableSlice{
+				indexable{ID: id2, IndexedField: value2},
+			},
+		},
+		{
+			name:   "where with upper bound desc",
+			bounds: []Bound{Where(compound("a")), UpperBound(compound("2020-05-02"))},
+			desc:   true,
+			expected: indexableSlice{
+				indexable{ID: id2, IndexedField: value2},
+				indexable{ID: id1, IndexedField: value1},
+			},
+		},
+		{
+			name:   "where with lower bound desc",
+			bounds: []Bound{Where(compound("a")), LowerBound(compound("2020-05-02"))},
+			desc:   true,
+			expected: indexableSlice{
+				indexable{ID: id3, IndexedField: value3},
+				indexable{ID: id2, IndexedField: value2},
+			},
+		},
+		{
+			name:   "where full compound key",
+			bounds: []Bound{Where(compound("b", "2020-05-02"))},
+			expected: indexableSlice{
+				indexable{ID: id1, IndexedField: value1},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, teardown := prep(t, existing)
+			defer teardown()
+
+			sl := indexableSlice{}
+			var q Queryable = &sl
+			if tt.desc {
+				q = Desc(q)
+			}
+
+			err := s.View(func(tx *bolt.Tx) error {
+				return (&Tx{tx}).Scan(q, tt.bounds)
+			})
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expected, sl)
+		})
+	}
+}
+
+func Test_store_ListRange_EncodedKeys(t *testing.T) {
+	ik := func(v int64) string { return string(keys.Int64(v)) }
+
+	existing := bucket{
+		bucketName: bucket{
+			id1: bt(&indexable{ID: id1, IndexedField: value1}),
+			id2: bt(&indexable{ID: id2, IndexedField: value2}),
+			id3: bt(&indexable{ID: id3, IndexedField: value3}),
+		},
+		indexBucketName: bucket{
+			ik(-300): bucket{id1: []byte{}},
+			ik(-2):   bucket{id2: []byte{}},
+			ik(40):   bucket{id3: []byte{}},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		bounds   []Bound
+		expected indexableSlice
+	}{
+		{
+			name:   "negative lower bound",
+			bounds: []Bound{LowerBound(index(ik(-10)))},
+			expected: indexableSlice{
+				indexable{ID: id2, IndexedField: value2},
+				indexable{ID: id3, IndexedField: value3},
+			},
+		},
+		{
+			name:   "range across zero",
+			bounds: []Bound{LowerBound(index(ik(-300))), UpperBound(index(ik(0)))},
+			expected: indexableSlice{
+				indexable{ID: id1, IndexedField: value1},
+				indexable{ID: id2, IndexedField: value2},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, teardown := prep(t, existing)
+			defer teardown()
+
+			sl := indexableSlice{}
+
+			err := s.View(func(tx *bolt.Tx) error {
+				return (&Tx{tx}).Scan(&sl, tt.bounds)
+			})
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expected, sl)
+		})
+	}
+}
+
+func Test_store_ListDesc(t *testing.T) {
+	existing := bucket{
+		bucketName: bucket{
+			id1: bt(&indexable{ID: id1, IndexedField: value1}),
+			id2: bt(&indexable{ID: id2, IndexedField: value2}),
+			id3: bt(&indexable{ID: id3, IndexedField: value2}),
+		},
+		indexBucketName: bucket{
+			value1: bucket{id1: []byte{}},
+			value2: bucket{id2: []byte{}, id3: []byte{}},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		bounds   []Bound
+		argument func(q Queryable) Queryable
+		expected indexableSlice
+	}{
+		{
+			name: "list",
+			expected: indexableSlice{
+				indexable{ID: id3, IndexedField: value2},
+				indexable{ID: id2, IndexedField: value2},
+				indexable{ID: id1, IndexedField: value1},
+			},
+		},
+		{
+			name:     "list page",
+			argument: func(q Queryable) Queryable { return Page(q, 1, 1) },
+			expected: indexableSlice{
+				indexable{ID: id2, IndexedField: value2},
+			},
+		},
+		{
+			name:   "by",
+			bounds: []Bound{By(index(""))},
+			expected: indexableSlice{
+				indexable{ID: id3, IndexedField: value2},
+				indexable{ID: id2, IndexedField: value2},
+				indexable{ID: id1, IndexedField: value1},
+			},
+		},
+		{
+			name:   "where",
+			bounds: []Bound{Where(index(value2))},
+			expected: indexableSlice{
+				indexable{ID: id3, IndexedField: value2},
+				indexable{ID: id2, IndexedField: value2},
+			},
+		},
+		{
+			name:   "upper bound between keys",
+			bounds: []Bound{UpperBound(index("value1x"))},
+			expected: indexableSlice{
+				indexable{ID: id1, IndexedField: value1},
+			},
+		},
+		{
+			name:     "lower bound with page",
+			bounds:   []Bound{LowerBound(index(value1))},
+			argument: func(q Queryable) Queryable { return Page(q, 0, 2) },
+			expected: indexableSlice{
+				indexable{ID: id3, IndexedField: value2},
+				indexable{ID: id2, IndexedField: value2},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, teardown := prep(t, existing)
+			defer teardown()
+
+			sl := indexableSlice{}
+			var q Queryable = &sl
+			if tt.argument != nil {
+				q = tt.argument(q)
+			}
+
+			err := s.View(func(tx *bolt.Tx) error {
+				return (&Tx{tx}).Scan(Desc(q), tt.bounds)
+			})
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expected, sl)
+		})
+	}
+}
+
+func Test_store_ListExclusiveAndPrefix(t *testing.T) {
+	existing := bucket{
+		bucketName: bucket{
+			id1: bt(&indexable{ID: id1, IndexedField: "2020-04-30"}),
+			id2: bt(&indexable{ID: id2, IndexedField: "2020-05-01"}),
+			id3: bt(&indexable{ID: id3, IndexedField: "2020-05-02"}),
+		},
+		indexBucketName: bucket{
+			"2020-04-30": bucket{id1: []byte{}},
+			"2020-05-01": bucket{id2: []byte{}},
+			"2020-05-02": bucket{id3: []byte{}},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		bounds   []Bound
+		desc     bool
+		expected []string
+	}{
+		{
+			name:     "after",
+			bounds:   []Bound{After(index("2020-05-01"))},
+			expected: []string{id3},
+		},
+		{
+			name:     "before",
+			bounds:   []Bound{Before(index("2020-05-01"))},
+			expected: []string{id1},
+		},
+		{
+			name:     "half-open window",
+			bounds:   []Bound{LowerBound(index("2020-04-30")), Before(index("2020-05-02"))},
+			expected: []string{id1, id2},
+		},
+		{
+			name:     "after and before desc",
+			bounds:   []Bound{After(index("2020-04-30")), Before(index("2020-05-02"))},
+			desc:     true,
+			expected: []string{id2},
+		},
+		{
+			name:     "before desc",
+			bounds:   []Bound{Before(index("2020-05-02"))},
+			desc:     true,
+			expected: []string{id2, id1},
+		},
+		{
+			name:     "prefix",
+			bounds:   []Bound{Prefix(index("2020-05"))},
+			expected: []string{id2, id3},
+		},
+		{
+			name:     "prefix desc",
+			bounds:   []Bound{Prefix(index("2020-05"))},
+			desc:     true,
+			expected: []string{id3, id2},
+		},
+		{
+			name:     "prefix with after",
+			bounds:   []Bound{Prefix(index("2020-")), After(index("04-30"))},
+			expected: []string{id2, id3},
+		},
+		{
+			name:   "prefix not matching",
+			bounds: []Bound{Prefix(index("2021"))},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, teardown := prep(t, existing)
+			defer teardown()
+
+			sl := indexableSlice{}
+			var q Queryable = &sl
+			if tt.desc {
+				q = Desc(q)
+			}
+
+			err := s.View(func(tx *bolt.Tx) error {
+				return (&Tx{tx}).Scan(q, tt.bounds)
+			})
+			assert.Nil(t, err)
+
+			var ids []string
+			for _, v := range sl {
+				ids = append(ids, v.ID)
+			}
+			assert.Equal(t, tt.expected, ids)
+		})
+	}
+}
+
+func Test_store_ScanCursor(t *testing.T) {
+	existing := bucket{
+		bucketName: bucket{
+			id1: bt(&indexable{ID: id1, IndexedField: value1}),
+			id2: bt(&indexable{ID: id2, IndexedField: value2}),
+			id3: bt(&indexable{ID: id3, IndexedField: value2}),
+		},
+		indexBucketName: bucket{
+			value1: bucket{id1: []byte{}},
+			value2: bucket{id2: []byte{}, id3: []byte{}},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		bounds   []Bound
+		desc     bool
+		limit    int
+		expected [][]string
+	}{
+		{
+			name:     "list",
+			limit:    2,
+			expected: [][]string{{id1, id2}, {id3}},
+		},
+		{
+			name:     "list desc",
+			desc:     true,
+			limit:    2,
+			expected: [][]string{{id3, id2}, {id1}},
+		},
+		{
+			name:     "by",
+			bounds:   []Bound{By(index(""))},
+			limit:    2,
+			expected: [][]string{{id1, id2}, {id3}},
+		},
+		{
+			name:     "where",
+			bounds:   []Bound{Where(index(value2))},
+			limit:    1,
+			expected: [][]string{{id2}, {id3}, nil},
+		},
+		{
+			name:     "range",
+			bounds:   []Bound{LowerBound(index(value1)), UpperBound(index(value2))},
+			limit:    1,
+			expected: [][]string{{id1}, {id2}, {id3}, nil},
+		},
+		{
+			name:     "range desc",
+			bounds:   []Bound{LowerBound(index(value1))},
+			desc:     true,
+			limit:    2,
+			expected: [][]string{{id3, id2}, {id1}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, teardown := prep(t, existing)
+			defer teardown()
+
+			var token []byte
+			for _, expected := range tt.expected {
+				sl := indexableSlice{}
+				c := &Cursor{Queryable: &sl, Limit: tt.limit, Token: token}
+
+				var q Queryable = c
+				if tt.desc {
+					q = Desc(q)
+				}
+
+				err := s.View(func(tx *bolt.Tx) error {
+					return (&Tx{tx}).Scan(q, tt.bounds)
+				})
+				assert.Nil(t, err)
+
+				var ids []string
+				for _, v := range sl {
+					ids = append(ids, v.ID)
+				}
+				assert.Equal(t, expected, ids)
+				token = c.Token
+			}
+		})
+	}
+}
+
+func Test_store_ScanCursor_Reuse(t *testing.T) {
+	s, teardown := prep(t, bucket{
+		bucketName: bucket{
+			id1:   bt(&indexable{ID: id1, IndexedField: value1}),
+			id2:   bt(&indexable{ID: id2, IndexedField: value2}),
+			id3:   bt(&indexable{ID: id3, IndexedField: value3}),
+			"id4": bt(&indexable{ID: "id4", IndexedField: value3}),
+		},
+		indexBucketName:       bucket{},
+		masterIndexBucketName: bucket{},
+	})
+	defer teardown()
+
+	sl := indexableSlice{}
+	c := &Cursor{Queryable: &sl, Limit: 2}
+
+	var pages [][]string
+	for {
+		sl = sl[:0]
+		err := s.View(func(tx *bolt.Tx) error {
+			return (&Tx{tx}).Scan(c, nil)
+		})
+		assert.Nil(t, err)
+
+		var ids []string
+		for _, v := range sl {
+			ids = append(ids, v.ID)
+		}
+		pages = append(pages, ids)
+		if len(sl) < c.Limit {
+			break
+		}
+	}
+
+	assert.Equal(t, [][]string{{id1, id2}, {id3, "id4"}, nil}, pages)
+}
+
+func Test_store_ScanCursor_Insert(t *testing.T) {
+	s, teardown := prep(t, bucket{
+		bucketName: bucket{
+			id1: bt(&indexable{ID: id1, IndexedField: value1}),
+			id3: bt(&indexable{ID: id3, IndexedField: value3}),
+		},
+		indexBucketName:       bucket{},
+		masterIndexBucketName: bucket{},
+	})
+	defer teardown()
+
+	first := indexableSlice{}
+	c := &Cursor{Queryable: &first, Limit: 1}
+	err := s.View(func(tx *bolt.Tx) error {
+		return (&Tx{tx}).Scan(c, nil)
+	})
+	assert.Nil(t, err)
+
+	err = s.Update(func(tx *bolt.Tx) error {
+		return (&Tx{tx}).Put(&indexable{ID: "id0", IndexedField: value2})
+	})
+	assert.Nil(t, err)
+
+	second := indexableSlice{}
+	err = s.View(func(tx *bolt.Tx) error {
+		return (&Tx{tx}).Scan(&Cursor{Queryable: &second, Limit: 1, Token: c.Token}, nil)
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, indexableSlice{{ID: id1, IndexedField: value1}}, first)
+	assert.Equal(t, indexableSlice{{ID: id3, IndexedField: value3}}, second)
+
+	err = s.View(func(tx *bolt.Tx) error {
+		return (&Tx{tx}).Scan(&Cursor{Queryable: &second, Token: []byte("x")}, nil)
+	})
+	assert.Equal(t, errInvalidToken, err)
+}
+
+func Test_store_Count(t *testing.T) {
+	existing := bucket{
+		bucketName: bucket{
+			id1: bt(&indexable{ID: id1, IndexedField: value1}),
+			id2: bt(&indexable{ID: id2, IndexedField: value2}),
+		},
+		indexBucketName: bucket{
+			value1: bucket{id1: []byte{}},
+			value2: bucket{id2: []byte{}, id3: []byte{}},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		bounds   []Bound
+		argument func(q Queryable) Queryable
+		expected int
+	}{
+		{name: "list", expected: 2},
+		{name: "by", bounds: []Bound{By(index(""))}, expected: 3},
+		{name: "where without reading values", bounds: []Bound{Where(index(value2))}, expected: 2},
+		{name: "range", bounds: []Bound{After(index(value1))}, expected: 2},
+		{name: "prefix", bounds: []Bound{Prefix(index("value"))}, expected: 3},
+		{
+			name:     "page",
+			bounds:   []Bound{By(index(""))},
+			argument: func(q Queryable) Queryable { return Page(q, 1, 0) },
+			expected: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, teardown := prep(t, existing)
+			defer teardown()
+
+			c := &Count{Bucket: indexableSlice{}}
+			var q Queryable = c
+			if tt.argument != nil {
+				q = tt.argument(q)
+			}
+
+			err := s.View(func(tx *bolt.Tx) error {
+				return (&Tx{tx}).Scan(q, tt.bounds)
+			})
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expected, c.Total)
+		})
+	}
+}
+
+func Test_store_ScanDangling(t *testing.T) {
+	existing := bucket{
+		bucketName: bucket{
+			id1: bt(&indexable{ID: id1, IndexedField: value1}),
+			id3: bt(&indexable{ID: id3, IndexedField: value2}),
+		},
+		indexBucketName: bucket{
+			value1: bucket{id1: []byte{}},
+			value2: bucket{id2: []byte{}, id3: []byte{}},
+		},
+	}
+	dangling := &ErrDanglingIndex{
+		Bucket: []byte(indexBucketName),
+		Key:    []byte(value2),
+		PK:     []byte(id2),
+	}
+	stop := errors.New("stop")
+
+	tests := []struct {
+		name          string
+		bounds        []Bound
+		argument      func(q Queryable, reported *[]*ErrDanglingIndex) Queryable
+		expected      indexableSlice
+		reported      []*ErrDanglingIndex
+		expectedError error
+	}{
+		{
+			name:          "where fails by default",
+			bounds:        []Bound{Where(index(value2))},
+			expectedError: dangling,
+		},
+		{
+			name:          "by fails by default",
+			bounds:        []Bound{By(index(""))},
+			expectedError: dangling,
+		},
+		{
+			name:          "range fails by default",
+			bounds:        []Bound{LowerBound(index(value1))},
+			expectedError: dangling,
+		},
+		{
+			name:     "skip",
+			bounds:   []Bound{By(index(""))},
+			argument: func(q Queryable, _ *[]*ErrDanglingIndex) Queryable { return SkipDangling(q) },
+			expected: indexableSlice{
+				indexable{ID: id1, IndexedField: value1},
+				indexable{ID: id3, IndexedField: value2},
+			},
+		},
+		{
+			name:   "report and skip under page",
+			bounds: []Bound{LowerBound(index(value1))},
+			argument: func(q Queryable, reported *[]*ErrDanglingIndex) Queryable {
+				return Page(OnDangling(q, func(e *ErrDanglingIndex) error {
+					*reported = append(*reported, e)
+					return nil
+				}), 1, 2)
+			},
+			expected: indexableSlice{indexable{ID: id3, IndexedField: value2}},
+			reported: []*ErrDanglingIndex{dangling},
+		},
+		{
+			name:   "report and stop",
+			bounds: []Bound{Where(index(value2))},
+			argument: func(q Queryable, _ *[]*ErrDanglingIndex) Queryable {
+				return OnDangling(q, func(*ErrDanglingIndex) error { return stop })
+			},
+			expectedError: stop,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, teardown := prep(t, existing)
+			defer teardown()
+
+			sl := indexableSlice{}
+			var (
+				q        Queryable = &sl
+				reported []*ErrDanglingIndex
+			)
+			if tt.argument != nil {
+				q = tt.argument(q, &reported)
+			}
+
+			err := s.View(func(tx *bolt.Tx) error {
+				return (&Tx{tx}).Scan(q, tt.bounds)
+			})
+
+			if tt.expectedError != nil {
+				assert.Equal(t, tt.expectedError, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expected, sl)
+			assert.Equal(t, tt.reported, reported)
+		})
+	}
+}
+
+func Test_store_ScanFilter(t *testing.T) {
+	existing := bucket{
+		bucketName: bucket{
+			id1: bt(&indexable{ID: id1, IndexedField: value1}),
+			id2: bt(&indexable{ID: id2, IndexedField: value2}),
+			id3: bt(&indexable{ID: id3, IndexedField: value2}),
+		},
+		indexBucketName: bucket{
+			value1: bucket{id1: []byte{}},
+			value2: bucket{id2: []byte{}, id3: []byte{}},
+		},
+	}
+	notID2 := func(pk, _ []byte) bool { return string(pk) != id2 }
+
+	tests := []struct {
+		name     string
+		bounds   []Bound
+		argument func(q Queryable) Queryable
+		expected indexableSlice
+	}{
+		{
+			name:     "list",
+			argument: func(q Queryable) Queryable { return Filter(q, notID2) },
+			expected: indexableSlice{
+				indexable{ID: id1, IndexedField: value1},
+				indexable{ID: id3, IndexedField: value2},
+			},
+		},
+		{
+			name:     "where",
+			bounds:   []Bound{Where(index(value2))},
+			argument: func(q Queryable) Queryable { return Filter(q, notID2) },
+			expected: indexableSlice{indexable{ID: id3, IndexedField: value2}},
+		},
+		{
+			name:   "by with page counting matches only",
+			bounds: []Bound{By(index(""))},
+			argument: func(q Queryable) Queryable {
+				return Page(Filter(q, notID2), 1, 1)
+			},
+			expected: indexableSlice{indexable{ID: id3, IndexedField: value2}},
+		},
+		{
+			name:   "range with two filters",
+			bounds: []Bound{LowerBound(index(value1))},
+			argument: func(q Queryable) Queryable {
+				return Filter(Filter(q, notID2), func(_, v []byte) bool {
+					return !bytes.Contains(v, []byte(value1))
+				})
+			},
+			expected: indexableSlice{indexable{ID: id3, IndexedField: value2}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, teardown := prep(t, existing)
+			defer teardown()
+
+			sl := indexableSlice{}
+			err := s.View(func(tx *bolt.Tx) error {
+				return (&Tx{tx}).Scan(tt.argument(&sl), tt.bounds)
+			})
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expected, sl)
+		})
+	}
+
+	t.Run("count reads values", func(t *testing.T) {
+		s, teardown := prep(t, existing)
+		defer teardown()
+
+		c := &Count{Bucket: indexableSlice{}}
+		err := s.View(func(tx *bolt.Tx) error {
+			return (&Tx{tx}).Scan(Filter(c, notID2), []Bound{Where(index(value2))})
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, 1, c.Total)
+	})
+}
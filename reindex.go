@@ -0,0 +1,277 @@
+package binx
+
+import (
+	"bytes"
+
+	bolt "github.com/coreos/bbolt"
+	"github.com/pkg/errors"
+)
+
+// ReindexOptions tune Reindex.
+type ReindexOptions struct {
+	// Index limits the rebuild to a single index bucket. All index buckets
+	// of the collection are rebuilt when it is nil.
+	Index Bucket
+	// ChunkSize is the number of records indexed per write transaction,
+	// DefaultReindexChunkSize when zero.
+	ChunkSize int
+	// Resume is the ErrReindex.Last of a failed Reindex. When it is set,
+	// indexes are not cleared again and records stored after it are indexed.
+	Resume []byte
+}
+
+const DefaultReindexChunkSize = 1000
+
+// Reindex rebuilds index entries of the records stored in bucket from
+// scratch. decode turns a stored value back into the Indexable that was
+// put. The index buckets of the collection, and its master index bucket
+// unless a single index is rebuilt, are emptied first, then records are
+// indexed. Both are done in chunks, each in its own transaction, so that
+// large buckets do not need one huge write transaction. Index buckets
+// belong to a single collection, see Schema.Register.
+//
+// Records put while Reindex runs are indexed as usual. Scans running
+// alongside it may miss records that have not been indexed yet, and unique
+// indexes do not stop puts from taking the keys of records that have not
+// been indexed yet. A chunk running into such a conflict fails with an
+// ErrUniqueViolation wrapped in an ErrReindex: indexes stay incomplete until
+// the conflict is resolved and Reindex is run again, with Resume set to
+// ErrReindex.Last.
+func (d *DB) Reindex(bucket, masterBucket []byte, decode func(value []byte) (Indexable, error), options *ReindexOptions) error {
+	if options == nil {
+		options = &ReindexOptions{}
+	}
+	size := options.ChunkSize
+	if size <= 0 {
+		size = DefaultReindexChunkSize
+	}
+
+	var only []byte
+	if options.Index != nil {
+		only = options.Index.BucketKey()
+	}
+
+	last := options.Resume
+	if last == nil {
+		var clear [][]byte
+		err := d.View(func(tx *Tx) (err error) {
+			clear, err = indexBuckets(tx.Tx, bucket, masterBucket, only, d.schema)
+			return err
+		})
+		if err != nil {
+			return errors.Wrap(err, "clear indexes")
+		}
+		if only == nil {
+			clear = append(clear, masterBucket)
+		}
+
+		for _, k := range clear {
+			for done := false; !done; {
+				err := d.Update(func(tx *Tx) (err error) {
+					done, err = clearChunk(tx.Tx, k, size)
+					return err
+				})
+				if err != nil {
+					return errors.Wrapf(err, "clear bucket %s", string(k))
+				}
+			}
+		}
+		last = []byte{}
+	}
+
+	for {
+		var (
+			done bool
+			next []byte
+		)
+		err := d.Update(func(tx *Tx) (err error) {
+			next, done, err = reindexChunk(tx.Tx, bucket, masterBucket, only, last, size, decode)
+			return err
+		})
+		if err != nil {
+			return &ErrReindex{Last: last, Err: errors.Wrap(err, "reindex")}
+		}
+		if done {
+			return nil
+		}
+		last = next
+	}
+}
+
+// indexBuckets returns the index buckets of a collection, or only the one
+// named only: the ones the master index refers to and, when s is set, the
+// ones registered for the collection.
+func indexBuckets(tx *bolt.Tx, bucket, masterBucket, only []byte, s *Schema) ([][]byte, error) {
+	if tx.Bucket(bucket) == nil {
+		return nil, errors.New("cannot get bucket " + string(bucket))
+	}
+	mib := tx.Bucket(masterBucket)
+	if mib == nil {
+		return nil, errors.New("master index bucket cannot be found")
+	}
+
+	if only != nil {
+		return [][]byte{only}, nil
+	}
+
+	var (
+		indexes [][]byte
+		seen    = map[string]bool{}
+	)
+	add := func(k []byte) {
+		if !seen[string(k)] {
+			seen[string(k)] = true
+			indexes = append(indexes, append([]byte(nil), k...))
+		}
+	}
+
+	if s != nil {
+		for _, c := range s.collections {
+			if bytes.Equal(c.bucket, bucket) {
+				for _, i := range c.indexes {
+					add(i)
+				}
+			}
+		}
+	}
+
+	err := mib.ForEach(func(k, _ []byte) error {
+		ib := mib.Bucket(k)
+		if ib == nil {
+			return nil
+		}
+		return ib.ForEach(func(k, _ []byte) error {
+			add(k)
+			return nil
+		})
+	})
+	return indexes, err
+}
+
+// clearChunk deletes up to size keys of the bucket key, along with their
+// nested buckets. It reports whether the bucket is empty.
+func clearChunk(tx *bolt.Tx, key []byte, size int) (bool, error) {
+	b := tx.Bucket(key)
+	if b == nil {
+		return true, nil
+	}
+
+	var keys, nested [][]byte
+	c := b.Cursor()
+	for k, v := c.First(); k != nil && len(keys)+len(nested) < size; k, v = c.Next() {
+		if v == nil {
+			nested = append(nested, copyBytes(k))
+		} else {
+			keys = append(keys, copyBytes(k))
+		}
+	}
+
+	for _, k := range nested {
+		if err := b.DeleteBucket(k); err != nil {
+			return false, err
+		}
+	}
+	for _, k := range keys {
+		if err := b.Delete(k); err != nil {
+			return false, err
+		}
+	}
+	return len(keys)+len(nested) < size, nil
+}
+
+// reindexChunk indexes up to size records stored after the key last, or
+// from the first one when last is empty. It returns the last key indexed and
+// whether the bucket has been fully walked.
+func reindexChunk(tx *bolt.Tx, bucket, masterBucket, only, last []byte, size int, decode func([]byte) (Indexable, error)) ([]byte, bool, error) {
+	bkt := tx.Bucket(bucket)
+	if bkt == nil {
+		return nil, false, errors.New("cannot get bucket " + string(bucket))
+	}
+	mib := tx.Bucket(masterBucket)
+	if mib == nil {
+		return nil, false, errors.New("master index bucket cannot be found")
+	}
+
+	c := direction{Cursor: bkt.Cursor()}
+	k, v := c.first()
+	if len(last) > 0 {
+		k, v = c.seekAfter(last)
+	}
+
+	for n := 0; k != nil; k, v = c.next() {
+		if n == size {
+			return last, false, nil
+		}
+		n++
+
+		idx, err := decode(v)
+		if err != nil {
+			return nil, false, errors.Wrapf(err, "decode %s", string(k))
+		}
+		if !bytes.Equal(idx.UniqueKey(), k) {
+			return nil, false, errors.Errorf("record %s decoded with key %s", string(k), string(idx.UniqueKey()))
+		}
+
+		if only == nil {
+			err = processIndexable(tx, idx)
+		} else {
+			err = reindexOne(tx, mib, idx, only)
+		}
+		if err != nil {
+			return nil, false, errors.Wrapf(err, "index %s", string(k))
+		}
+
+		last = append(last[:0:0], k...)
+	}
+
+	return last, true, nil
+}
+
+// reindexOne replaces the entries of idx in the index bucket only, leaving
+// its other index entries alone.
+func reindexOne(tx *bolt.Tx, mib *bolt.Bucket, idx Indexable, only []byte) error {
+	sub := subset{Indexable: idx}
+	for _, i := range idx.Indexes() {
+		if bytes.Equal(i.BucketKey(), only) {
+			sub.indexes = append(sub.indexes, i)
+		}
+	}
+
+	if err := checkUniqueIndexes(tx, sub); err != nil {
+		return err
+	}
+
+	if ib := mib.Bucket(idx.UniqueKey()); ib != nil {
+		if err := cleanupIndex(tx, ib, only, ib.Get(only), idx.UniqueKey()); err != nil {
+			return err
+		}
+
+		var err error
+		if ib.Bucket(only) != nil {
+			err = ib.DeleteBucket(only)
+		} else {
+			err = ib.Delete(only)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := createIndexes(tx, sub); err != nil {
+		return errors.Wrap(err, "create indexes")
+	}
+	return createMasterIndex(tx, mib, sub)
+}
+
+// subset is an Indexable limited to some of its indexes.
+type subset struct {
+	Indexable
+	indexes []Index
+}
+
+func (s subset) Indexes() []Index { return s.indexes }
+
+// Reindex rebuilds the index entries of the collection, see DB.Reindex.
+func (c *Collection[T]) Reindex(d *DB, options *ReindexOptions) error {
+	return d.Reindex(c.Bucket, c.Master, c.decode, options)
+}
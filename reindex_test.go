@@ -0,0 +1,146 @@
+package binx
+
+import (
+	"testing"
+
+	bolt "github.com/coreos/bbolt"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_DB_Reindex(t *testing.T) {
+	// corrupt drops the byRole entries and the master index of id2 and adds
+	// a stale byEmail entry of id3.
+	corrupt := func(tx *bolt.Tx) error {
+		if err := tx.DeleteBucket([]byte("byRole")); err != nil {
+			return err
+		}
+		if _, err := tx.CreateBucket([]byte("byRole")); err != nil {
+			return err
+		}
+		if err := tx.Bucket([]byte("usersMasterIndex")).DeleteBucket([]byte(id2)); err != nil {
+			return err
+		}
+		b, err := tx.Bucket([]byte("byEmail")).CreateBucket([]byte("x@example.com"))
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(id3), nil)
+	}
+
+	tests := []struct {
+		name    string
+		options *ReindexOptions
+		stale   bool
+	}{
+		{"all indexes", nil, false},
+		{"all indexes in chunks", &ReindexOptions{ChunkSize: 1}, false},
+		{"one index", &ReindexOptions{Index: byRole(""), ChunkSize: 2}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, teardown := prepUsers(t)
+			defer teardown()
+
+			expected := bucket{}
+			assert.Nil(t, db.db.View(readBuckets(&expected)))
+
+			assert.Nil(t, db.db.Update(corrupt))
+			assert.Nil(t, users.Reindex(db, tt.options))
+
+			if tt.stale {
+				expected["byEmail"].(bucket)["x@example.com"] = bucket{id3: []byte{}}
+				expected["usersMasterIndex"].(bucket)[id2] = bucket{"byRole": []byte("dev")}
+			}
+
+			state := bucket{}
+			assert.Nil(t, db.db.View(readBuckets(&state)))
+			assert.Equal(t, expected, state)
+		})
+	}
+}
+
+func Test_DB_Reindex_UniqueViolation(t *testing.T) {
+	db, teardown := prepUsers(t)
+	defer teardown()
+
+	err := db.db.Update(func(tx *bolt.Tx) error {
+		v, err := users.codec().Marshal(user{ID: "id4", Email: "a@example.com"})
+		if err != nil {
+			return err
+		}
+		return tx.Bucket([]byte("users")).Put([]byte("id4"), v)
+	})
+	assert.Nil(t, err)
+
+	err = users.Reindex(db, nil)
+	var uv *ErrUniqueViolation
+	assert.True(t, errors.As(err, &uv), "%v", err)
+}
+
+func Test_DB_Reindex_Resume(t *testing.T) {
+	db, teardown := prepUsers(t)
+	defer teardown()
+
+	expected := bucket{}
+	assert.Nil(t, db.db.View(readBuckets(&expected)))
+
+	// id15 takes the email of id1 while the byEmail entry of id1 is cleared
+	put := func(email string) error {
+		return db.db.Update(func(tx *bolt.Tx) error {
+			v, err := users.codec().Marshal(user{ID: "id15", Email: email})
+			if err != nil {
+				return err
+			}
+			return tx.Bucket([]byte("users")).Put([]byte("id15"), v)
+		})
+	}
+	assert.Nil(t, put("a@example.com"))
+
+	err := users.Reindex(db, &ReindexOptions{ChunkSize: 1})
+	var re *ErrReindex
+	if assert.True(t, errors.As(err, &re), "%v", err) {
+		assert.Equal(t, []byte(id1), re.Last)
+	}
+	var uv *ErrUniqueViolation
+	assert.True(t, errors.As(err, &uv), "%v", err)
+
+	assert.Nil(t, put("d@example.com"))
+	assert.Nil(t, users.Reindex(db, &ReindexOptions{ChunkSize: 1, Resume: re.Last}))
+
+	err = db.View(func(tx *Tx) error {
+		problems, err := users.Check(tx, db.schema)
+		assert.Empty(t, problems)
+		return err
+	})
+	assert.Nil(t, err)
+}
+
+func Test_DB_Reindex_Dangling(t *testing.T) {
+	db, teardown := prepUsers(t)
+	defer teardown()
+
+	// id2 is deleted along with its master index, leaving its index entries
+	err := db.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.Bucket([]byte("users")).Delete([]byte(id2)); err != nil {
+			return err
+		}
+		return tx.Bucket([]byte("usersMasterIndex")).DeleteBucket([]byte(id2))
+	})
+	assert.Nil(t, err)
+
+	assert.Nil(t, users.Reindex(db, &ReindexOptions{ChunkSize: 1}))
+
+	err = db.View(func(tx *Tx) error {
+		devs, err := users.Scan(tx, Where(byRole("dev")))
+		assert.Nil(t, err)
+		for _, u := range devs {
+			assert.NotEqual(t, id2, u.ID)
+		}
+
+		problems, err := users.Check(tx, db.schema)
+		assert.Empty(t, problems)
+		return err
+	})
+	assert.Nil(t, err)
+}
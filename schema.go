@@ -0,0 +1,122 @@
+package binx
+
+import "github.com/pkg/errors"
+
+// Schema describes the buckets used by registered Indexable types, so they
+// can be created and validated once instead of on every write.
+type Schema struct {
+	collections []collection
+}
+
+type collection struct {
+	bucket  []byte
+	master  []byte
+	indexes [][]byte
+}
+
+// Register adds the primary, master index and index buckets of idx. Index
+// buckets are taken from idx.Indexes(); the ones it does not return for a
+// zero value, like multi-valued indexes with no keys, are passed in indexes.
+// An index bucket belongs to a single collection: Check, Repair and Reindex
+// rely on it, so a schema registering one for two collections is invalid.
+func (s *Schema) Register(idx Indexable, indexes ...Bucket) {
+	c := collection{
+		bucket: idx.BucketKey(),
+		master: idx.MasterIndexBucketKey(),
+	}
+
+	seen := map[string]bool{}
+	for _, i := range idx.Indexes() {
+		if !seen[string(i.BucketKey())] {
+			seen[string(i.BucketKey())] = true
+			c.indexes = append(c.indexes, i.BucketKey())
+		}
+	}
+	for _, i := range indexes {
+		if !seen[string(i.BucketKey())] {
+			seen[string(i.BucketKey())] = true
+			c.indexes = append(c.indexes, i.BucketKey())
+		}
+	}
+
+	s.collections = append(s.collections, c)
+}
+
+func (s *Schema) buckets() ([][]byte, error) {
+	const (
+		primary = "primary"
+		master  = "master index"
+		index   = "index"
+	)
+
+	var (
+		keys   [][]byte
+		roles  = map[string]string{}
+		owners = map[string][]byte{}
+	)
+
+	add := func(key []byte, role string, owner []byte) error {
+		if len(key) == 0 {
+			return errors.Errorf("%s bucket key cannot be empty", role)
+		}
+		if r, ok := roles[string(key)]; ok {
+			if r == role && role == index {
+				return errors.Errorf("index bucket %s registered for %s and %s", string(key), string(owners[string(key)]), string(owner))
+			}
+			return errors.Errorf("bucket %s registered as %s and %s", string(key), r, role)
+		}
+		roles[string(key)] = role
+		owners[string(key)] = owner
+		keys = append(keys, key)
+		return nil
+	}
+
+	for _, c := range s.collections {
+		if err := add(c.bucket, primary, c.bucket); err != nil {
+			return nil, err
+		}
+		if err := add(c.master, master, c.bucket); err != nil {
+			return nil, err
+		}
+		for _, i := range c.indexes {
+			if err := add(i, index, c.bucket); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return keys, nil
+}
+
+// EnsureSchema validates s and creates all of its missing buckets.
+func (w *Tx) EnsureSchema(s *Schema) error {
+	keys, err := s.buckets()
+	if err != nil {
+		return errors.Wrap(err, "invalid schema")
+	}
+
+	for _, k := range keys {
+		if _, err := w.Tx.CreateBucketIfNotExists(k); err != nil {
+			return errors.Wrapf(err, "create bucket %s", string(k))
+		}
+	}
+
+	return nil
+}
+
+// CheckSchema validates s and reports the first of its buckets that is
+// missing.
+func (r *Tx) CheckSchema(s *Schema) error {
+	keys, err := s.buckets()
+	if err != nil {
+		return errors.Wrap(err, "invalid schema")
+	}
+
+	for _, k := range keys {
+		if r.Tx.Bucket(k) == nil {
+			return errors.Errorf("bucket %s not found", string(k))
+		}
+	}
+
+	return nil
+}
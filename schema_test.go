@@ -0,0 +1,132 @@
+package binx
+
+import (
+	"testing"
+
+	bolt "github.com/coreos/bbolt"
+	"github.com/stretchr/testify/assert"
+)
+
+type otherIndex string
+
+func (e otherIndex) BucketKey() []byte { return []byte("otherIndexBucketName") }
+func (e otherIndex) Key() []byte       { return []byte(e) }
+
+type pet struct {
+	Name  string
+	Owner string
+}
+
+// pets index into byEmail, the index bucket of users, which a schema
+// rejects.
+var pets = &Collection[pet]{
+	Bucket:  []byte("pets"),
+	Master:  []byte("petsMasterIndex"),
+	Key:     func(p pet) []byte { return []byte(p.Name) },
+	Indexes: func(p pet) []Index { return []Index{byEmail(p.Owner)} },
+}
+
+func Test_store_EnsureSchema(t *testing.T) {
+	tests := []struct {
+		name          string
+		existing      bucket
+		register      func(s *Schema)
+		expected      bucket
+		expectedError string
+	}{
+		{
+			name: "create all buckets",
+			register: func(s *Schema) {
+				s.Register(&indexable{}, otherIndex(""))
+			},
+			expected: bucket{
+				bucketName:             bucket{},
+				masterIndexBucketName:  bucket{},
+				indexBucketName:        bucket{},
+				"otherIndexBucketName": bucket{},
+			},
+		},
+		{
+			name: "keep existing buckets",
+			existing: bucket{
+				bucketName: bucket{id1: bt(&indexable{ID: id1})},
+			},
+			register: func(s *Schema) {
+				s.Register(&indexable{})
+			},
+			expected: bucket{
+				bucketName:            bucket{id1: bt(&indexable{ID: id1})},
+				masterIndexBucketName: bucket{},
+				indexBucketName:       bucket{},
+			},
+		},
+		{
+			name: "primary bucket registered twice",
+			register: func(s *Schema) {
+				s.Register(&indexable{})
+				s.Register(&taggedIndexable{}, index(""))
+			},
+			expectedError: "invalid schema: bucket bucketName registered as primary and primary",
+		},
+		{
+			name: "index bucket used as primary bucket",
+			register: func(s *Schema) {
+				s.Register(&indexable{}, indexableSlice{})
+			},
+			expectedError: "invalid schema: bucket bucketName registered as primary and index",
+		},
+		{
+			name: "index bucket shared by two collections",
+			register: func(s *Schema) {
+				users.Register(s)
+				pets.Register(s)
+			},
+			expectedError: "invalid schema: index bucket byEmail registered for users and pets",
+		},
+		{
+			name: "index bucket listed twice by a collection",
+			register: func(s *Schema) {
+				users.Register(s, byRole(""), byRole(""), byEmail(""))
+			},
+			expected: bucket{
+				"users":            bucket{},
+				"usersMasterIndex": bucket{},
+				"byRole":           bucket{},
+				"byEmail":          bucket{},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, teardown := prep(t, tt.existing)
+			defer teardown()
+
+			s := &Schema{}
+			tt.register(s)
+
+			err := db.View(func(tx *bolt.Tx) error {
+				return (&Tx{tx}).CheckSchema(s)
+			})
+			assert.NotNil(t, err)
+
+			err = db.Update(func(tx *bolt.Tx) error {
+				return (&Tx{tx}).EnsureSchema(s)
+			})
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+				return
+			}
+			assert.Nil(t, err)
+
+			err = db.View(func(tx *bolt.Tx) error {
+				return (&Tx{tx}).CheckSchema(s)
+			})
+			assert.Nil(t, err)
+
+			state := bucket{}
+			err = db.View(readBuckets(&state))
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expected, state)
+		})
+	}
+}
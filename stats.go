@@ -0,0 +1,155 @@
+package binx
+
+import (
+	"sort"
+
+	bolt "github.com/coreos/bbolt"
+	"github.com/pkg/errors"
+)
+
+// HotKeys is the number of index keys with the most entries listed in
+// IndexStats.Hot.
+const HotKeys = 10
+
+// Stats holds the statistics of the collections of a Schema, see Tx.Stats.
+type Stats struct {
+	Collections []CollectionStats
+}
+
+// CollectionStats describes a primary bucket, its master index bucket and
+// its index buckets. Sizes are the bytes in use by bucket pages, as counted
+// by bolt.Bucket.Stats.
+type CollectionStats struct {
+	Bucket     []byte
+	Records    int
+	Size       int
+	MasterSize int
+	Indexes    []IndexStats
+}
+
+// IndexStats describes an index bucket: Keys is the number of distinct index
+// keys, Entries the number of primary keys held under all of them, and the
+// fan-out is the number of primary keys held under a single index key.
+type IndexStats struct {
+	Bucket    []byte
+	Keys      int
+	Entries   int
+	MaxFanOut int
+	AvgFanOut float64
+	Hot       []KeyCount
+	Size      int
+}
+
+// KeyCount is an index key and the number of primary keys held under it.
+type KeyCount struct {
+	Key   []byte
+	Count int
+}
+
+// Stats returns the statistics of every collection registered in s.
+func (r *Tx) Stats(s *Schema) (*Stats, error) {
+	st := &Stats{}
+	for _, c := range s.collections {
+		bkt := r.Tx.Bucket(c.bucket)
+		if bkt == nil {
+			return nil, errors.New("cannot get bucket " + string(c.bucket))
+		}
+		mib := r.Tx.Bucket(c.master)
+		if mib == nil {
+			return nil, errors.New("master index bucket cannot be found")
+		}
+
+		bs := bkt.Stats()
+		cs := CollectionStats{
+			Bucket:     c.bucket,
+			Records:    bs.KeyN,
+			Size:       size(bs),
+			MasterSize: size(mib.Stats()),
+		}
+
+		for _, i := range c.indexes {
+			is, err := r.IndexStats(bucketKey(i))
+			if err != nil {
+				return nil, err
+			}
+			cs.Indexes = append(cs.Indexes, is)
+		}
+
+		st.Collections = append(st.Collections, cs)
+	}
+	return st, nil
+}
+
+// IndexStats returns the statistics of the index bucket of b. It walks every
+// key of the index, but reads no primary key.
+func (r *Tx) IndexStats(b Bucket) (IndexStats, error) {
+	ix := r.Tx.Bucket(b.BucketKey())
+	if ix == nil {
+		return IndexStats{}, ErrIdxNotFound
+	}
+
+	is := IndexStats{
+		Bucket: b.BucketKey(),
+		Size:   size(ix.Stats()),
+	}
+
+	c := ix.Cursor()
+	for k, v := c.First(); k != nil; k, v = c.Next() {
+		if v != nil {
+			continue
+		}
+
+		n := countKeys(ix.Bucket(k), 0)
+		is.Keys++
+		is.Entries += n
+		if n > is.MaxFanOut {
+			is.MaxFanOut = n
+		}
+		is.Hot = hot(is.Hot, k, n)
+	}
+
+	if is.Keys > 0 {
+		is.AvgFanOut = float64(is.Entries) / float64(is.Keys)
+	}
+	return is, nil
+}
+
+// Stats returns the statistics of the collections of the schema the
+// database was opened with.
+func (d *DB) Stats() (st *Stats, err error) {
+	if d.schema == nil {
+		return nil, errors.New("stats need a schema")
+	}
+	err = d.View(func(tx *Tx) error {
+		st, err = tx.Stats(d.schema)
+		return err
+	})
+	return st, err
+}
+
+// hot adds key to the keys with the most entries, kept sorted by count.
+func hot(keys []KeyCount, key []byte, n int) []KeyCount {
+	if len(keys) == HotKeys && n <= keys[HotKeys-1].Count {
+		return keys
+	}
+
+	i := sort.Search(len(keys), func(i int) bool { return keys[i].Count < n })
+	keys = append(keys, KeyCount{})
+	copy(keys[i+1:], keys[i:])
+	keys[i] = KeyCount{Key: copyBytes(key), Count: n}
+
+	if len(keys) > HotKeys {
+		keys = keys[:HotKeys]
+	}
+	return keys
+}
+
+// size returns the bytes in use by a bucket and its nested buckets. Nested
+// buckets small enough to be inlined are part of the values of their parent;
+// a bucket that is inlined itself holds no nested bucket.
+func size(s bolt.BucketStats) int {
+	if s.BranchPageN+s.LeafPageN == 0 {
+		return s.InlineBucketInuse
+	}
+	return s.BranchInuse + s.LeafInuse
+}
@@ -0,0 +1,72 @@
+package binx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_DB_Stats(t *testing.T) {
+	db, teardown := prepUsers(t)
+	defer teardown()
+
+	st, err := db.Stats()
+	assert.Nil(t, err)
+	assert.Len(t, st.Collections, 1)
+
+	c := st.Collections[0]
+	assert.Equal(t, []byte("users"), c.Bucket)
+	assert.Equal(t, 3, c.Records)
+	assert.True(t, c.Size > 0)
+	assert.True(t, c.MasterSize > 0)
+
+	for _, is := range c.Indexes {
+		assert.True(t, is.Size > 0)
+		is.Size = 0
+
+		switch string(is.Bucket) {
+		case "byEmail":
+			assert.Equal(t, IndexStats{
+				Bucket:    []byte("byEmail"),
+				Keys:      3,
+				Entries:   3,
+				MaxFanOut: 1,
+				AvgFanOut: 1,
+				Hot: []KeyCount{
+					{[]byte("a@example.com"), 1},
+					{[]byte("b@example.com"), 1},
+					{[]byte("c@example.com"), 1},
+				},
+			}, is)
+		case "byRole":
+			assert.Equal(t, IndexStats{
+				Bucket:    []byte("byRole"),
+				Keys:      2,
+				Entries:   3,
+				MaxFanOut: 2,
+				AvgFanOut: 1.5,
+				Hot: []KeyCount{
+					{[]byte("dev"), 2},
+					{[]byte("admin"), 1},
+				},
+			}, is)
+		default:
+			t.Errorf("unexpected index %s", is.Bucket)
+		}
+	}
+	assert.Len(t, c.Indexes, 2)
+}
+
+func Test_hot(t *testing.T) {
+	var keys []KeyCount
+	for i := 0; i < HotKeys+5; i++ {
+		keys = hot(keys, []byte{byte(i)}, i%7)
+	}
+
+	assert.Len(t, keys, HotKeys)
+	assert.Equal(t, KeyCount{[]byte{6}, 6}, keys[0])
+	assert.Equal(t, KeyCount{[]byte{13}, 6}, keys[1])
+	for i := 1; i < len(keys); i++ {
+		assert.True(t, keys[i-1].Count >= keys[i].Count)
+	}
+}
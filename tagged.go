@@ -0,0 +1,313 @@
+package binx
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/gimalay/binx/keys"
+	"github.com/pkg/errors"
+)
+
+// TaggedCollection is a Collection derived from binx struct tags by Tagged.
+type TaggedCollection[T any] struct {
+	*Collection[T]
+	indexes map[string]*taggedIndex
+	order   []string
+}
+
+type taggedIndex struct {
+	bucket []byte
+	fields [][]int
+	unique bool
+	multi  bool
+}
+
+var (
+	timeType        = reflect.TypeOf(time.Time{})
+	bytesType       = reflect.TypeOf([]byte(nil))
+	errTaggedStruct = errors.New("tagged type must be a struct or a pointer to a struct")
+)
+
+// Tagged returns a collection of T stored in bucket and described by binx
+// tags on the fields of T:
+//
+//	type User struct {
+//		ID      int64     `binx:"pk"`
+//		Email   string    `binx:"index=byEmail,unique"`
+//		Roles   []string  `binx:"index=byRole"`
+//		Tenant  string    `binx:"index=byTenantCreated"`
+//		Created time.Time `binx:"index=byTenantCreated"`
+//	}
+//
+// Slice fields index every element and fields sharing an index name make a
+// compound index in field order. Integers, floats and times are encoded with
+// the keys package, so ranges follow their natural order. Index buckets are
+// named bucket.name and the master index bucket bucket#master. Empty values
+// of single-field indexes are not indexed.
+func Tagged[T any](bucket string, codec Codec) (*TaggedCollection[T], error) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, errTaggedStruct
+	}
+
+	c := &TaggedCollection[T]{indexes: map[string]*taggedIndex{}}
+
+	var pk []int
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, ok := f.Tag.Lookup("binx")
+		if !ok || tag == "-" {
+			continue
+		}
+
+		var (
+			names  []string
+			unique bool
+		)
+		for _, opt := range strings.Split(tag, ",") {
+			switch {
+			case opt == "pk":
+				if pk != nil {
+					return nil, errors.Errorf("field %s: primary key is already set", f.Name)
+				}
+				if !keyType(f.Type) {
+					return nil, errors.Errorf("field %s: %s cannot be used as a key", f.Name, f.Type)
+				}
+				pk = f.Index
+			case opt == "unique":
+				unique = true
+			case strings.HasPrefix(opt, "index="):
+				names = append(names, strings.TrimPrefix(opt, "index="))
+			default:
+				return nil, errors.Errorf("field %s: unknown binx tag option %q", f.Name, opt)
+			}
+		}
+		if unique && len(names) == 0 {
+			return nil, errors.Errorf("field %s: unique needs an index", f.Name)
+		}
+
+		for _, name := range names {
+			multi := f.Type.Kind() == reflect.Slice && f.Type != bytesType
+			ft := f.Type
+			if multi {
+				ft = ft.Elem()
+			}
+			if name == "" || !keyType(ft) {
+				return nil, errors.Errorf("field %s: invalid index %q", f.Name, name)
+			}
+
+			idx, ok := c.indexes[name]
+			if !ok {
+				idx = &taggedIndex{bucket: []byte(bucket + "." + name)}
+				c.indexes[name] = idx
+				c.order = append(c.order, name)
+			}
+			idx.fields = append(idx.fields, f.Index)
+			idx.unique = idx.unique || unique
+			idx.multi = idx.multi || multi
+
+			if idx.multi && len(idx.fields) > 1 {
+				return nil, errors.Errorf("index %s: slice fields cannot be part of a compound index", name)
+			}
+		}
+	}
+
+	if pk == nil {
+		return nil, errors.New("tagged type has no primary key field")
+	}
+
+	c.Collection = &Collection[T]{
+		Bucket:  []byte(bucket),
+		Master:  []byte(bucket + "#master"),
+		Codec:   codec,
+		Key:     func(v T) []byte { return encodeKey(field(v, pk)) },
+		Indexes: c.values,
+	}
+
+	return c, nil
+}
+
+// Index returns the entry of the named index for values, given in the order
+// of its fields. Leading values only build a prefix of a compound index, to
+// be used with Prefix or a compound Where.
+func (c *TaggedCollection[T]) Index(name string, values ...interface{}) (Index, error) {
+	idx, ok := c.indexes[name]
+	if !ok {
+		return nil, errors.Errorf("index %s not found", name)
+	}
+	if len(values) == 0 || len(values) > len(idx.fields) {
+		return nil, errors.Errorf("index %s takes up to %d values", name, len(idx.fields))
+	}
+
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	parts := make([][]byte, 0, len(values))
+	for i, v := range values {
+		ft := t.FieldByIndex(idx.fields[i]).Type
+		if idx.multi {
+			ft = ft.Elem()
+		}
+
+		rv, ok := convert(reflect.ValueOf(v), ft)
+		if !ok {
+			return nil, errors.Errorf("index %s: %T %v cannot be used as %s", name, v, v, ft)
+		}
+		parts = append(parts, encodeKey(rv))
+	}
+
+	if len(idx.fields) == 1 {
+		return multi{idx.bucket, parts[0]}, nil
+	}
+	return multi{idx.bucket, Tuple(parts...)}, nil
+}
+
+// Register adds the buckets of the collection, including all of its index
+// buckets, to s.
+func (c *TaggedCollection[T]) Register(s *Schema) {
+	bkts := make([]Bucket, 0, len(c.order))
+	for _, name := range c.order {
+		bkts = append(bkts, multi{bucket: c.indexes[name].bucket})
+	}
+	c.Collection.Register(s, bkts...)
+}
+
+func (c *TaggedCollection[T]) values(v T) []Index {
+	var idx []Index
+
+	for _, name := range c.order {
+		ti := c.indexes[name]
+
+		var entries []Index
+		switch {
+		case ti.multi:
+			f := field(v, ti.fields[0])
+			for i := 0; i < f.Len(); i++ {
+				if k := encodeKey(f.Index(i)); len(k) > 0 {
+					entries = append(entries, multi{ti.bucket, k})
+				}
+			}
+		case len(ti.fields) == 1:
+			if k := encodeKey(field(v, ti.fields[0])); len(k) > 0 {
+				entries = append(entries, multi{ti.bucket, k})
+			}
+		default:
+			parts := make([][]byte, 0, len(ti.fields))
+			for _, f := range ti.fields {
+				parts = append(parts, encodeKey(field(v, f)))
+			}
+			entries = append(entries, multi{ti.bucket, Tuple(parts...)})
+		}
+
+		for _, e := range entries {
+			if ti.unique {
+				e = Unique(e)
+			}
+			idx = append(idx, e)
+		}
+	}
+
+	return idx
+}
+
+// field returns a field of the struct v, or of the struct v points to. A nil
+// pointer stands for the zero struct.
+func field(v interface{}, index []int) reflect.Value {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			rv = reflect.New(rv.Type().Elem())
+		}
+		rv = rv.Elem()
+	}
+	return rv.FieldByIndex(index)
+}
+
+func keyType(t reflect.Type) bool {
+	if t == timeType || t == bytesType {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// convert converts the index value v to the field type to. It reports false
+// for conversions such as int to string, and for numbers the field type
+// cannot hold, like 3.9 or -1 for a uint field.
+func convert(v reflect.Value, to reflect.Type) (reflect.Value, bool) {
+	if !v.IsValid() {
+		return reflect.Value{}, false
+	}
+	from := v.Type()
+	if from.AssignableTo(to) {
+		return v.Convert(to), true
+	}
+
+	number := func(t reflect.Type) bool {
+		return t.Kind() >= reflect.Int && t.Kind() <= reflect.Float64
+	}
+	if number(from) && number(to) {
+		if to.Kind() >= reflect.Uint && to.Kind() <= reflect.Uintptr && negative(v) {
+			return reflect.Value{}, false
+		}
+		c := v.Convert(to)
+		if !c.Convert(from).Equal(v) {
+			return reflect.Value{}, false
+		}
+		return c, true
+	}
+
+	if from.Kind() == to.Kind() && from.ConvertibleTo(to) {
+		return v.Convert(to), true
+	}
+	return reflect.Value{}, false
+}
+
+func negative(v reflect.Value) bool {
+	switch {
+	case v.Kind() >= reflect.Int && v.Kind() <= reflect.Int64:
+		return v.Int() < 0
+	case v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64:
+		return v.Float() < 0
+	}
+	return false
+}
+
+// encodeKey encodes a value of a type accepted by keyType.
+func encodeKey(v reflect.Value) []byte {
+	if v.Type() == timeType {
+		return keys.Time(v.Interface().(time.Time))
+	}
+	if v.Type() == bytesType {
+		return append([]byte(nil), v.Bytes()...)
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return []byte(v.String())
+	case reflect.Bool:
+		if v.Bool() {
+			return []byte{1}
+		}
+		return []byte{0}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return keys.Int64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return keys.Uint64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return keys.Float64(v.Float())
+	}
+	return nil
+}
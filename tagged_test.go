@@ -0,0 +1,216 @@
+package binx
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gimalay/binx/keys"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type account struct {
+	ID      int64     `binx:"pk"`
+	Email   string    `binx:"index=byEmail,unique"`
+	Roles   []string  `binx:"index=byRole"`
+	Tenant  string    `binx:"index=byTenantCreated"`
+	Created time.Time `binx:"index=byTenantCreated"`
+	Balance float64   `binx:"index=byBalance"`
+	Note    string
+}
+
+func Test_Tagged(t *testing.T) {
+	_ = os.Remove(dbPath)
+
+	accounts, err := Tagged[account]("accounts", nil)
+	assert.Nil(t, err)
+
+	s := &Schema{}
+	accounts.Register(s)
+
+	db, err := Open(dbPath, 0600, nil, s)
+	assert.Nil(t, err)
+	defer func() { assert.Nil(t, db.Close()) }()
+
+	t0 := time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)
+	err = db.Update(func(tx *Tx) error {
+		for _, a := range []account{
+			{ID: -20, Email: "a@example.com", Roles: []string{"admin", "dev"}, Tenant: "a", Created: t0, Balance: -1.5},
+			{ID: 3, Email: "b@example.com", Roles: []string{"dev"}, Tenant: "a", Created: t0.Add(time.Hour), Balance: 10},
+			{ID: 100, Tenant: "b", Created: t0, Balance: 2},
+		} {
+			if err := accounts.Put(tx, a); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	assert.Nil(t, err)
+
+	ids := func(as []account) (r []int64) {
+		for _, a := range as {
+			r = append(r, a.ID)
+		}
+		return r
+	}
+	index := func(name string, values ...interface{}) Index {
+		i, err := accounts.Index(name, values...)
+		assert.Nil(t, err)
+		return i
+	}
+
+	err = db.View(func(tx *Tx) error {
+		all, err := accounts.Scan(tx)
+		assert.Nil(t, err)
+		assert.Equal(t, []int64{-20, 3, 100}, ids(all))
+
+		_, err = accounts.Get(tx, index("byEmail", "b@example.com").Key())
+		assert.Equal(t, ErrNotFound, err)
+
+		a, err := accounts.Get(tx, accounts.Key(account{ID: 3}))
+		assert.Nil(t, err)
+		assert.Equal(t, "b@example.com", a.Email)
+
+		byEmail, err := accounts.Scan(tx, Where(index("byEmail", "b@example.com")))
+		assert.Nil(t, err)
+		assert.Equal(t, []int64{3}, ids(byEmail))
+
+		devs, err := accounts.Scan(tx, Where(index("byRole", "dev")))
+		assert.Nil(t, err)
+		assert.Equal(t, []int64{-20, 3}, ids(devs))
+
+		positive, err := accounts.Scan(tx, After(index("byBalance", 0)))
+		assert.Nil(t, err)
+		assert.Equal(t, []int64{100, 3}, ids(positive))
+
+		recent, err := accounts.Scan(tx,
+			Where(index("byTenantCreated", "a")),
+			After(Compound(index("byTenantCreated", "a"), keys.Time(t0))),
+		)
+		assert.Nil(t, err)
+		assert.Equal(t, []int64{3}, ids(recent))
+
+		tenant, err := accounts.Scan(tx, Prefix(index("byTenantCreated", "a")))
+		assert.Nil(t, err)
+		assert.Equal(t, []int64{-20, 3}, ids(tenant))
+		return nil
+	})
+	assert.Nil(t, err)
+
+	err = db.Update(func(tx *Tx) error {
+		return tx.Put(accounts.Indexable(account{ID: 4, Email: "a@example.com"}))
+	})
+	var violation *ErrUniqueViolation
+	assert.True(t, errors.As(err, &violation))
+
+	_, err = accounts.Index("byTenantCreated", 1)
+	assert.NotNil(t, err)
+	_, err = accounts.Index("missing", 1)
+	assert.NotNil(t, err)
+}
+
+func Test_Tagged_Pointer(t *testing.T) {
+	_ = os.Remove(dbPath)
+
+	accounts, err := Tagged[*account]("accounts", nil)
+	assert.Nil(t, err)
+
+	s := &Schema{}
+	accounts.Register(s)
+
+	db, err := Open(dbPath, 0600, nil, s)
+	assert.Nil(t, err)
+	defer func() { assert.Nil(t, db.Close()) }()
+
+	assert.Equal(t, accounts.Key(&account{}), accounts.Key(nil))
+	assert.Equal(t, accounts.Indexes(&account{}), accounts.Indexes(nil))
+
+	a := &account{ID: 7, Email: "a@example.com", Roles: []string{"dev"}}
+	err = db.Update(func(tx *Tx) error {
+		return accounts.Put(tx, a)
+	})
+	assert.Nil(t, err)
+
+	err = db.View(func(tx *Tx) error {
+		got, err := accounts.Get(tx, accounts.Key(a))
+		assert.Nil(t, err)
+		assert.Equal(t, a, got)
+
+		i, err := accounts.Index("byRole", "dev")
+		assert.Nil(t, err)
+		devs, err := accounts.Scan(tx, Where(i))
+		assert.Nil(t, err)
+		assert.Equal(t, []*account{a}, devs)
+		return nil
+	})
+	assert.Nil(t, err)
+}
+
+func Test_Tagged_Index_Conversions(t *testing.T) {
+	type counter struct {
+		ID  string  `binx:"pk"`
+		Age int     `binx:"index=byAge"`
+		N   uint    `binx:"index=byN"`
+		F   float32 `binx:"index=byF"`
+	}
+	counters, err := Tagged[counter]("counters", nil)
+	assert.Nil(t, err)
+
+	tests := []struct {
+		name  string
+		value interface{}
+		err   string
+	}{
+		{name: "byAge", value: int8(3)},
+		{name: "byAge", value: 3.0},
+		{name: "byAge", value: 3.9, err: "index byAge: float64 3.9 cannot be used as int"},
+		{name: "byN", value: 3},
+		{name: "byN", value: -1, err: "index byN: int -1 cannot be used as uint"},
+		{name: "byN", value: -1.0, err: "index byN: float64 -1 cannot be used as uint"},
+		{name: "byF", value: 0.5},
+		{name: "byF", value: 0.1, err: "index byF: float64 0.1 cannot be used as float32"},
+		{name: "byAge", value: "3", err: "index byAge: string 3 cannot be used as int"},
+	}
+	for _, tt := range tests {
+		i, err := counters.Index(tt.name, tt.value)
+		if tt.err != "" {
+			assert.EqualError(t, err, tt.err)
+			continue
+		}
+		if assert.Nil(t, err, "%s %v", tt.name, tt.value) {
+			assert.NotEmpty(t, i.Key())
+		}
+	}
+
+	three, _ := counters.Index("byAge", 3)
+	same, _ := counters.Index("byAge", 3.0)
+	assert.Equal(t, three.Key(), same.Key())
+}
+
+func Test_Tagged_Invalid(t *testing.T) {
+	type noKey struct {
+		Name string `binx:"index=byName"`
+	}
+	type badOption struct {
+		ID string `binx:"pk,primary"`
+	}
+	type uniqueKey struct {
+		ID string `binx:"pk,unique"`
+	}
+	type badType struct {
+		ID   string            `binx:"pk"`
+		Tags map[string]string `binx:"index=byTag"`
+	}
+
+	_, err := Tagged[noKey]("b", nil)
+	assert.NotNil(t, err)
+	_, err = Tagged[badOption]("b", nil)
+	assert.NotNil(t, err)
+	_, err = Tagged[*badType]("b", nil)
+	assert.NotNil(t, err)
+	_, err = Tagged[uniqueKey]("b", nil)
+	assert.EqualError(t, err, "field ID: unique needs an index")
+	_, err = Tagged[string]("b", nil)
+	assert.Equal(t, errTaggedStruct, err)
+}
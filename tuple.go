@@ -0,0 +1,44 @@
+package binx
+
+import "github.com/gimalay/binx/keys"
+
+// Tuple encodes parts into a single key whose byte order follows the order
+// of the parts compared one by one. Every part is encoded with keys.Bytes,
+// so Tuple(a, b) equals Tuple(a) followed by Tuple(b) and a tuple of leading
+// parts is a byte prefix of the full tuple.
+func Tuple(parts ...[]byte) []byte {
+	var key []byte
+	for _, p := range parts {
+		key = append(key, keys.Bytes(p)...)
+	}
+	return key
+}
+
+// SplitTuple decodes a key built by Tuple back into its parts.
+func SplitTuple(key []byte) ([][]byte, error) {
+	var parts [][]byte
+	for len(key) > 0 {
+		part, rest, err := keys.DecodeBytes(key)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, part)
+		key = rest
+	}
+	return parts, nil
+}
+
+// Compound returns an index over several fields keyed by Tuple(parts...).
+// A compound index built from leading parts only can be used in Where
+// together with LowerBound and UpperBound holding the remaining parts.
+func Compound(b Bucket, parts ...[]byte) Index {
+	return compound{b.BucketKey(), Tuple(parts...)}
+}
+
+type compound struct {
+	bucket []byte
+	key    []byte
+}
+
+func (c compound) BucketKey() []byte { return c.bucket }
+func (c compound) Key() []byte       { return c.key }
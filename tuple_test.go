@@ -0,0 +1,59 @@
+package binx
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Tuple_Order(t *testing.T) {
+	tests := []struct {
+		name         string
+		lower, upper [][]byte
+	}{
+		{"first part decides", [][]byte{[]byte("a"), []byte("z")}, [][]byte{[]byte("b"), []byte("a")}},
+		{"shorter part first", [][]byte{[]byte("a"), []byte("z")}, [][]byte{[]byte("ab"), []byte("a")}},
+		{"second part decides", [][]byte{[]byte("a"), []byte("a")}, [][]byte{[]byte("a"), []byte("b")}},
+		{"zero byte in part", [][]byte{[]byte("a"), []byte("z")}, [][]byte{[]byte("a\x00"), []byte("a")}},
+		{"leading parts first", [][]byte{[]byte("a")}, [][]byte{[]byte("a"), []byte("")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, -1, bytes.Compare(Tuple(tt.lower...), Tuple(tt.upper...)))
+		})
+	}
+}
+
+func Test_SplitTuple(t *testing.T) {
+	tests := []struct {
+		name          string
+		parts         [][]byte
+		key           []byte
+		expectedError bool
+	}{
+		{name: "single part", parts: [][]byte{[]byte("a")}},
+		{name: "several parts", parts: [][]byte{[]byte("tenant"), []byte("2020-05-01")}},
+		{name: "escaped parts", parts: [][]byte{{0x00, 0xff, 0x00}, {}, {0x01}}},
+		{name: "not terminated", key: []byte("a"), expectedError: true},
+		{name: "invalid escape", key: []byte{'a', 0x00, 0x02}, expectedError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := tt.key
+			if key == nil {
+				key = Tuple(tt.parts...)
+				assert.Equal(t, key, append(Tuple(tt.parts[0]), Tuple(tt.parts[1:]...)...))
+			}
+
+			parts, err := SplitTuple(key)
+			if tt.expectedError {
+				assert.NotNil(t, err)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.parts, parts)
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package binx
 
 import (
+	"bytes"
+
 	bolt "github.com/coreos/bbolt"
 	"github.com/pkg/errors"
 )
@@ -21,12 +23,50 @@ func (w *Tx) Put(idx Indexable) error {
 	return errors.Wrap(put(bucket, idx), "put")
 }
 
+func (w *Tx) Delete(idx Indexable) error {
+	return w.DeleteByKey(idx.BucketKey(), idx.MasterIndexBucketKey(), idx.UniqueKey())
+}
+
+func (w *Tx) DeleteByKey(bucket, masterBucket, key []byte) error {
+	tx := w.Tx
+
+	if len(key) == 0 {
+		return errors.New(errEmptyKey)
+	}
+
+	bkt := tx.Bucket(bucket)
+	if bkt == nil {
+		return errors.New("cannot get bucket " + string(bucket))
+	}
+
+	if bkt.Get(key) == nil {
+		return ErrNotFound
+	}
+
+	mib := tx.Bucket(masterBucket)
+	if mib == nil {
+		return errors.New("master index bucket cannot be found")
+	}
+
+	err := cleanupIndexes(tx, mib, key)
+	if err != nil {
+		return errors.Wrap(err, "cleanup indexes")
+	}
+
+	return errors.Wrap(bkt.Delete(key), "delete")
+}
+
 func processIndexable(tx *bolt.Tx, idx Indexable) error {
 	bkt := tx.Bucket(idx.MasterIndexBucketKey())
 	if bkt == nil {
 		return errors.New("master index bucket cannot be found")
 	}
-	err := cleanupIndexes(tx, bkt, idx)
+	err := checkUniqueIndexes(tx, idx)
+	if err != nil {
+		return err
+	}
+
+	err = cleanupIndexes(tx, bkt, idx.UniqueKey())
 	if err != nil {
 		return errors.Wrap(err, "cleanup indexes")
 	}
@@ -38,6 +78,45 @@ func processIndexable(tx *bolt.Tx, idx Indexable) error {
 	return createMasterIndex(tx, bkt, idx)
 }
 
+// checkUniqueIndexes reports the first unique index key of idx held by
+// another record. Dangling entries, of records missing from the bucket of
+// idx, hold no key: they are left for Check and Repair to report.
+func checkUniqueIndexes(tx *bolt.Tx, idx Indexable) error {
+	bkt := tx.Bucket(idx.BucketKey())
+	if bkt == nil {
+		return errors.New("cannot get bucket " + string(idx.BucketKey()))
+	}
+
+	for _, i := range idx.Indexes() {
+		if u, ok := i.(UniqueConstraint); !ok || !u.Unique() {
+			continue
+		}
+
+		idxBkt := tx.Bucket(i.BucketKey())
+		if idxBkt == nil {
+			continue
+		}
+
+		b := idxBkt.Bucket(i.Key())
+		if b == nil {
+			continue
+		}
+
+		c := b.Cursor()
+		for k, _ := c.First(); k != nil; k, _ = c.Next() {
+			if !bytes.Equal(k, idx.UniqueKey()) && bkt.Get(k) != nil {
+				return &ErrUniqueViolation{
+					Bucket:   i.BucketKey(),
+					Key:      i.Key(),
+					Conflict: append([]byte(nil), k...),
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
 func createIndexes(tx *bolt.Tx, idx Indexable) error {
 	for _, i := range idx.Indexes() {
 		idxBkt := tx.Bucket(i.BucketKey())
@@ -64,37 +143,125 @@ func createIndexes(tx *bolt.Tx, idx Indexable) error {
 }
 
 func createMasterIndex(tx *bolt.Tx, mib *bolt.Bucket, idx Indexable) error {
-	if ib, err := mib.CreateBucketIfNotExists(idx.UniqueKey()); err == nil {
-		for _, i := range idx.Indexes() {
-			err := ib.Put(i.BucketKey(), i.Key())
+	ib, err := mib.CreateBucketIfNotExists(idx.UniqueKey())
+	if err != nil {
+		return err
+	}
+
+	keys := map[string][][]byte{}
+	for _, i := range idx.Indexes() {
+		keys[string(i.BucketKey())] = append(keys[string(i.BucketKey())], i.Key())
+	}
+
+	for bk, ks := range keys {
+		if len(ks) == 1 {
+			err := ib.Put([]byte(bk), ks[0])
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		mb, err := ib.CreateBucket([]byte(bk))
+		if err != nil {
+			return err
+		}
+		for _, k := range ks {
+			// a nil value would be taken for a nested bucket by DeleteBucket
+			err := mb.Put(k, []byte{})
 			if err != nil {
 				return err
 			}
 		}
 	}
+
 	return nil
 }
 
-func cleanupIndexes(tx *bolt.Tx, mib *bolt.Bucket, idx Indexable) error {
-	ib := mib.Bucket(idx.UniqueKey())
+func cleanupIndexes(tx *bolt.Tx, mib *bolt.Bucket, key []byte) error {
+	ib := mib.Bucket(key)
 	if ib == nil {
 		return nil
 	}
 
 	err := ib.ForEach(func(k, v []byte) error {
-		if b := tx.Bucket(k); b != nil {
-			if b2 := b.Bucket(v); b2 != nil {
-				return b2.Delete(idx.UniqueKey())
-			}
-		}
-		return nil
+		return cleanupIndex(tx, ib, k, v, key)
 	})
 
 	if err != nil {
 		return err
 	}
 
-	return mib.DeleteBucket(idx.UniqueKey())
+	return mib.DeleteBucket(key)
+}
+
+// cleanupIndex removes key from the index bucket k, given the entry v the
+// master index bucket ib holds for it, nil when the entry is a nested bucket.
+func cleanupIndex(tx *bolt.Tx, ib *bolt.Bucket, k, v, key []byte) error {
+	b := tx.Bucket(k)
+	if b == nil {
+		return nil
+	}
+
+	if v != nil {
+		return deleteIndexEntry(b, v, key)
+	}
+
+	mb := ib.Bucket(k)
+	if mb == nil {
+		return nil
+	}
+	return mb.ForEach(func(ik, _ []byte) error {
+		return deleteIndexEntry(b, ik, key)
+	})
+}
+
+// deleteIndexEntry removes key from the nested bucket of index key ik, and
+// the nested bucket itself once it is empty.
+func deleteIndexEntry(b *bolt.Bucket, ik, key []byte) error {
+	b2 := b.Bucket(ik)
+	if b2 == nil {
+		return nil
+	}
+	if err := b2.Delete(key); err != nil {
+		return err
+	}
+	if k, _ := b2.Cursor().First(); k == nil {
+		return b.DeleteBucket(ik)
+	}
+	return nil
+}
+
+// PruneIndexes deletes the empty nested buckets of index keys left in the
+// index buckets by writes that did not remove them, and returns how many
+// were deleted.
+func (w *Tx) PruneIndexes(indexes ...Bucket) (int, error) {
+	n := 0
+	for _, i := range indexes {
+		b := w.Tx.Bucket(i.BucketKey())
+		if b == nil {
+			return n, errors.Errorf("index bucket not found %v", string(i.BucketKey()))
+		}
+
+		var empty [][]byte
+		c := b.Cursor()
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			if v != nil {
+				continue
+			}
+			if k2, _ := b.Bucket(k).Cursor().First(); k2 == nil {
+				empty = append(empty, k)
+			}
+		}
+
+		for _, k := range empty {
+			if err := b.DeleteBucket(k); err != nil {
+				return n, err
+			}
+			n++
+		}
+	}
+	return n, nil
 }
 
 func put(b *bolt.Bucket, idx Indexable) (err error) {
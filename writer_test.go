@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	bolt "github.com/coreos/bbolt"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -44,7 +45,6 @@ func Test_store_Put_WithIndex(t *testing.T) {
 
 				indexBucketName: bucket{
 					value2: bucket{id1: []byte{}},
-					value1: bucket{},
 				},
 				masterIndexBucketName: bucket{id1: bucket{indexBucketName: []byte(value2)}},
 			},
@@ -85,7 +85,6 @@ func Test_store_Put_WithIndex(t *testing.T) {
 				},
 
 				indexBucketName: bucket{
-					value1: bucket{},
 					value2: bucket{id2: []byte{}},
 					value3: bucket{id1: []byte{}},
 				},
@@ -120,3 +119,281 @@ func Test_store_Put_WithIndex(t *testing.T) {
 		})
 	}
 }
+
+func Test_store_Delete(t *testing.T) {
+	existing := bucket{
+		bucketName: bucket{
+			id1: bt(&indexable{ID: id1, IndexedField: value1}),
+			id2: bt(&indexable{ID: id2, IndexedField: value1}),
+		},
+		indexBucketName: bucket{
+			value1: bucket{id1: []byte{}, id2: []byte{}},
+		},
+		masterIndexBucketName: bucket{
+			id1: bucket{indexBucketName: []byte(value1)},
+			id2: bucket{indexBucketName: []byte(value1)},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		argument      Indexable
+		expected      bucket
+		expectedError error
+	}{
+		{
+			name:     "Delete entry and its indexes",
+			argument: &indexable{ID: id1},
+			expected: bucket{
+				bucketName: bucket{
+					id2: bt(&indexable{ID: id2, IndexedField: value1}),
+				},
+				indexBucketName: bucket{
+					value1: bucket{id2: []byte{}},
+				},
+				masterIndexBucketName: bucket{
+					id2: bucket{indexBucketName: []byte(value1)},
+				},
+			},
+		},
+		{
+			name:          "Delete missing entry",
+			argument:      &indexable{ID: id3},
+			expectedError: ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, teardown := prep(t, existing)
+			defer teardown()
+
+			err := db.Update(func(tx *bolt.Tx) error {
+				return (&Tx{tx}).Delete(tt.argument)
+			})
+
+			if tt.expectedError != nil {
+				assert.Equal(t, tt.expectedError, err)
+				return
+			}
+
+			assert.Nil(t, err)
+			state := bucket{}
+			err = db.View(readBuckets(&state))
+			assert.Nil(t, err)
+
+			assert.Equal(t, tt.expected, state)
+		})
+	}
+}
+
+func Test_store_Put_UniqueIndex(t *testing.T) {
+	tests := []struct {
+		name          string
+		argument      []Indexable
+		expectedError error
+	}{
+		{
+			name: "Put 2 entries with different unique keys",
+			argument: []Indexable{
+				&uniqueIndexable{indexable{ID: id1, IndexedField: value1}},
+				&uniqueIndexable{indexable{ID: id2, IndexedField: value2}},
+			},
+		},
+		{
+			name: "Put same entry twice",
+			argument: []Indexable{
+				&uniqueIndexable{indexable{ID: id1, IndexedField: value1}},
+				&uniqueIndexable{indexable{ID: id1, IndexedField: value1}},
+			},
+		},
+		{
+			name: "Put entry taking released unique key",
+			argument: []Indexable{
+				&uniqueIndexable{indexable{ID: id1, IndexedField: value1}},
+				&uniqueIndexable{indexable{ID: id1, IndexedField: value2}},
+				&uniqueIndexable{indexable{ID: id2, IndexedField: value1}},
+			},
+		},
+		{
+			name: "Put 2 entries with same unique key",
+			argument: []Indexable{
+				&uniqueIndexable{indexable{ID: id1, IndexedField: value1}},
+				&uniqueIndexable{indexable{ID: id2, IndexedField: value1}},
+			},
+			expectedError: &ErrUniqueViolation{
+				Bucket:   []byte(indexBucketName),
+				Key:      []byte(value1),
+				Conflict: []byte(id1),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, teardown := prep(t, bucket{bucketName: bucket{}, indexBucketName: bucket{}, masterIndexBucketName: bucket{}})
+			defer teardown()
+
+			err := db.Update(func(tx *bolt.Tx) error {
+				for _, v := range tt.argument {
+					err := (&Tx{tx}).Put(v)
+					if err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+
+			if tt.expectedError == nil {
+				assert.Nil(t, err)
+				return
+			}
+
+			var violation *ErrUniqueViolation
+			assert.True(t, errors.As(err, &violation))
+			assert.Equal(t, tt.expectedError, violation)
+		})
+	}
+}
+
+func Test_store_Put_UniqueIndex_Dangling(t *testing.T) {
+	// id1 is gone but its entry is left in the unique index
+	db, teardown := prep(t, bucket{
+		bucketName: bucket{},
+		indexBucketName: bucket{
+			value1: bucket{id1: []byte{}},
+		},
+		masterIndexBucketName: bucket{},
+	})
+	defer teardown()
+
+	err := db.Update(func(tx *bolt.Tx) error {
+		return (&Tx{tx}).Put(&uniqueIndexable{indexable{ID: id2, IndexedField: value1}})
+	})
+	assert.Nil(t, err)
+
+	state := bucket{}
+	assert.Nil(t, db.View(readBuckets(&state)))
+	assert.Equal(t, bucket{id1: []byte{}, id2: []byte{}}, state[indexBucketName].(bucket)[value1])
+}
+
+func Test_store_Put_MultiIndex(t *testing.T) {
+	tests := []struct {
+		name     string
+		argument []Indexable
+		delete   []Indexable
+		expected bucket
+	}{
+		{
+			name: "Put entry with several keys",
+			argument: []Indexable{
+				&taggedIndexable{indexable{ID: id1}, []string{value1, value2}},
+			},
+			expected: bucket{
+				bucketName: bucket{
+					id1: bt(&taggedIndexable{indexable{ID: id1}, []string{value1, value2}}),
+				},
+				indexBucketName: bucket{
+					value1: bucket{id1: []byte{}},
+					value2: bucket{id1: []byte{}},
+				},
+				masterIndexBucketName: bucket{
+					id1: bucket{indexBucketName: bucket{value1: []byte{}, value2: []byte{}}},
+				},
+			},
+		},
+		{
+			name: "Put entry with updated keys",
+			argument: []Indexable{
+				&taggedIndexable{indexable{ID: id1}, []string{value1, value2}},
+				&taggedIndexable{indexable{ID: id1}, []string{value3}},
+			},
+			expected: bucket{
+				bucketName: bucket{
+					id1: bt(&taggedIndexable{indexable{ID: id1}, []string{value3}}),
+				},
+				indexBucketName: bucket{
+					value3: bucket{id1: []byte{}},
+				},
+				masterIndexBucketName: bucket{
+					id1: bucket{indexBucketName: []byte(value3)},
+				},
+			},
+		},
+		{
+			name: "Delete entry with several keys",
+			argument: []Indexable{
+				&taggedIndexable{indexable{ID: id1}, []string{value1, value2}},
+				&taggedIndexable{indexable{ID: id2}, []string{value2}},
+			},
+			delete: []Indexable{
+				&taggedIndexable{indexable: indexable{ID: id1}},
+			},
+			expected: bucket{
+				bucketName: bucket{
+					id2: bt(&taggedIndexable{indexable{ID: id2}, []string{value2}}),
+				},
+				indexBucketName: bucket{
+					value2: bucket{id2: []byte{}},
+				},
+				masterIndexBucketName: bucket{
+					id2: bucket{indexBucketName: []byte(value2)},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, teardown := prep(t, bucket{bucketName: bucket{}, indexBucketName: bucket{}, masterIndexBucketName: bucket{}})
+			defer teardown()
+
+			err := db.Update(func(tx *bolt.Tx) error {
+				for _, v := range tt.argument {
+					err := (&Tx{tx}).Put(v)
+					if err != nil {
+						return err
+					}
+				}
+				for _, v := range tt.delete {
+					err := (&Tx{tx}).Delete(v)
+					if err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+
+			assert.Nil(t, err)
+			state := bucket{}
+			err = db.View(readBuckets(&state))
+			assert.Nil(t, err)
+
+			assert.Equal(t, tt.expected, state)
+		})
+	}
+}
+
+func Test_store_PruneIndexes(t *testing.T) {
+	db, teardown := prep(t, bucket{
+		indexBucketName: bucket{
+			value1: bucket{},
+			value2: bucket{id1: []byte{}},
+			value3: bucket{},
+		},
+		"otherIndexBucketName": bucket{value1: bucket{}},
+	})
+	defer teardown()
+
+	var n int
+	err := db.Update(func(tx *bolt.Tx) (err error) {
+		n, err = (&Tx{tx}).PruneIndexes(index(""))
+		return err
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, n)
+
+	state := bucket{}
+	assert.Nil(t, db.View(readBuckets(&state)))
+	assert.Equal(t, bucket{
+		indexBucketName:        bucket{value2: bucket{id1: []byte{}}},
+		"otherIndexBucketName": bucket{value1: bucket{}},
+	}, state)
+}